@@ -0,0 +1,164 @@
+package cruncher
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// binaryVersion is the leading byte of the binary encoding. Fields are only
+// ever appended, so a reader accepts any version up to its own, decoding
+// the fields an older version has. Newer versions are rejected with
+// ErrParse.
+const binaryVersion = 1
+
+// binaryTopTerms is the number of most frequent values MarshalBinary includes
+const binaryTopTerms = jsonTopTerms
+
+// MarshalBinary encodes the stats in a compact versioned format for
+// exchanging stats between services. It includes the Name, Count, Min, Max,
+// Sum, Variance, Median, the distribution layout, counts and outliers
+// and the most frequent values.
+func (is IntStats) MarshalBinary() ([]byte, error) {
+	b := []byte{binaryVersion}
+	b = binary.AppendUvarint(b, uint64(len(is.Name)))
+	b = append(b, is.Name...)
+	b = binary.AppendVarint(b, is.Count)
+	b = binary.AppendVarint(b, is.Min)
+	b = binary.AppendVarint(b, is.Max)
+	sum := is.Sum
+	if sum == nil {
+		sum = new(big.Int)
+	}
+	b = binary.AppendVarint(b, int64(sum.Sign()))
+	magnitude := sum.Bytes()
+	b = binary.AppendUvarint(b, uint64(len(magnitude)))
+	b = append(b, magnitude...)
+	b = binary.LittleEndian.AppendUint64(b, math.Float64bits(is.Variance))
+	b = binary.AppendVarint(b, is.Median)
+	b = binary.AppendVarint(b, int64(is.Scale))
+	b = binary.AppendVarint(b, is.BucketSize)
+	b = binary.AppendVarint(b, is.FrequencyDistributionStartingValue)
+	b = binary.AppendVarint(b, is.OutlierBefore)
+	b = binary.AppendVarint(b, is.OutlierAfter)
+	b = binary.AppendUvarint(b, uint64(len(is.FrequencyDistribution)))
+	for _, count := range is.FrequencyDistribution {
+		b = binary.AppendVarint(b, count)
+	}
+	terms := is.GetTermFrequency(binaryTopTerms)
+	b = binary.AppendUvarint(b, uint64(len(terms)))
+	for _, term := range terms {
+		b = binary.AppendVarint(b, term.Value)
+		b = binary.AppendVarint(b, term.Frequency)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary decodes stats encoded by MarshalBinary. Mean and StdDev
+// are derived from the decoded Sum and Variance and ValueFrequency only
+// holds the most frequent values that were encoded.
+func (is *IntStats) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: binary stats are empty", ErrEmpty)
+	}
+	if version := data[0]; version == 0 || version > binaryVersion {
+		return fmt.Errorf("%w: unknown binary stats version %d, expected at most %d", ErrParse, version, binaryVersion)
+	}
+	d := decoder{data: data[1:]}
+	var s IntStats
+	s.Name = string(d.bytes())
+	s.Count = d.varint()
+	s.Min = d.varint()
+	s.Max = d.varint()
+	sign := d.varint()
+	s.Sum = new(big.Int).SetBytes(d.bytes())
+	if sign < 0 {
+		s.Sum.Neg(s.Sum)
+	}
+	s.Variance = d.float64()
+	s.Median = d.varint()
+	s.Scale = Scale(d.varint())
+	s.BucketSize = d.varint()
+	s.FrequencyDistributionStartingValue = d.varint()
+	s.OutlierBefore = d.varint()
+	s.OutlierAfter = d.varint()
+	if n := d.length(); n > 0 {
+		s.FrequencyDistribution = make([]int64, n)
+		for i := range s.FrequencyDistribution {
+			s.FrequencyDistribution[i] = d.varint()
+		}
+	}
+	if n := d.length(); n > 0 {
+		s.ValueFrequency = make(map[int64]int64, n)
+		for i := 0; i < n; i++ {
+			value := d.varint()
+			s.ValueFrequency[value] = d.varint()
+		}
+	}
+	if d.err != nil {
+		return d.err
+	}
+	if s.Count > 0 {
+		sum, _ := new(big.Float).SetInt(s.Sum).Float64()
+		s.Mean = sum / float64(s.Count)
+	}
+	s.StdDev = math.Sqrt(s.Variance)
+	*is = s
+	return nil
+}
+
+// decoder reads the fields of the binary encoding, recording the first
+// error so the fields can be read without checking each one
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: binary stats are truncated or corrupt", ErrParse)
+	}
+	d.data = nil
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// length reads a count of items that each take at least a byte
+func (d *decoder) length() int {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 || v > uint64(len(d.data)-n) {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return int(v)
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.length()
+	if d.err != nil {
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) float64() float64 {
+	if len(d.data) < 8 {
+		d.fail()
+		return 0
+	}
+	v := math.Float64frombits(binary.LittleEndian.Uint64(d.data))
+	d.data = d.data[8:]
+	return v
+}
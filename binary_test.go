@@ -0,0 +1,61 @@
+package cruncher
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithWindow(100), WithBuckets(8), WithName("latency"))
+	for i := int64(-500); i < 1500; i++ {
+		a.Add(i * i % 977)
+	}
+	is := a.GetStats()
+	data, err := is.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded IntStats
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Name != is.Name || decoded.Count != is.Count || decoded.Min != is.Min || decoded.Max != is.Max ||
+		decoded.Median != is.Median || decoded.Sum.Cmp(is.Sum) != 0 || decoded.Variance != is.Variance {
+		t.Errorf("Decoded %+v != %+v", decoded, is)
+	}
+	if actual, correct := decoded.Mean, is.Mean; actual != correct {
+		t.Errorf("Mean: %f != %f", actual, correct)
+	}
+	if !decoded.SameLayout(is) || !reflect.DeepEqual(decoded.FrequencyDistribution, is.FrequencyDistribution) ||
+		decoded.OutlierBefore != is.OutlierBefore || decoded.OutlierAfter != is.OutlierAfter {
+		t.Errorf("Distribution %v != %v", decoded.FrequencyDistribution, is.FrequencyDistribution)
+	}
+	if actual, correct := len(decoded.ValueFrequency), binaryTopTerms; actual != correct {
+		t.Errorf("Top terms: %d != %d", actual, correct)
+	}
+	for value, frequency := range decoded.ValueFrequency {
+		if actual, correct := frequency, is.ValueFrequency[value]; actual != correct {
+			t.Errorf("Frequency of %d: %d != %d", value, actual, correct)
+		}
+	}
+	if _, err := MergeStats(decoded, is); err != nil {
+		t.Errorf("Decoded stats should merge: %v", err)
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	data, _ := IntStats{Count: 1}.MarshalBinary()
+	data[0] = binaryVersion + 1
+	var is IntStats
+	if err := is.UnmarshalBinary(data); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("Unknown version: %v", err)
+	}
+	data[0] = binaryVersion
+	if err := is.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Errorf("Truncated stats should be an error")
+	}
+	if err := is.UnmarshalBinary(nil); err == nil {
+		t.Errorf("Empty stats should be an error")
+	}
+}
@@ -0,0 +1,33 @@
+package cruncher
+
+// updateBucketRange records value as observed within bucket i, it must be
+// called before the bucket's count is incremented
+func (a *Accumulator) updateBucketRange(i int, value int64) {
+	is := &a.intStats
+	if is.FrequencyDistribution[i] == 0 || value < is.BucketMin[i] {
+		is.BucketMin[i] = value
+	}
+	if is.FrequencyDistribution[i] == 0 || value > is.BucketMax[i] {
+		is.BucketMax[i] = value
+	}
+}
+
+// mergeBucketRange combines the observed bucket ranges of other, which must
+// have the same layout, before the counts are combined
+func (is *IntStats) mergeBucketRange(other IntStats) {
+	if is.BucketMin == nil || other.BucketMin == nil {
+		is.BucketMin, is.BucketMax = nil, nil
+		return
+	}
+	for i, count := range other.FrequencyDistribution {
+		if count == 0 {
+			continue
+		}
+		if is.FrequencyDistribution[i] == 0 || other.BucketMin[i] < is.BucketMin[i] {
+			is.BucketMin[i] = other.BucketMin[i]
+		}
+		if is.FrequencyDistribution[i] == 0 || other.BucketMax[i] > is.BucketMax[i] {
+			is.BucketMax[i] = other.BucketMax[i]
+		}
+	}
+}
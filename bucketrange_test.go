@@ -0,0 +1,51 @@
+package cruncher
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestBucketRange(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithBucketRange(), WithBuckets(10))
+	for i := 0; i < 5000; i++ {
+		a.Add(rand.Int63n(10000))
+	}
+	is := a.GetStats()
+	if actual, correct := len(is.BucketMin), len(is.FrequencyDistribution); actual != correct {
+		t.Fatalf("BucketMin: %d != %d", actual, correct)
+	}
+	for i, count := range is.FrequencyDistribution {
+		if count == 0 {
+			continue
+		}
+		lower := is.FrequencyDistributionStartingValue + int64(i)*is.BucketSize
+		upper := lower + is.BucketSize - 1
+		if is.BucketMin[i] < lower || is.BucketMax[i] > upper || is.BucketMin[i] > is.BucketMax[i] {
+			t.Errorf("Bucket %d observed range %d - %d isn't within %d - %d",
+				i, is.BucketMin[i], is.BucketMax[i], lower, upper)
+		}
+	}
+	var buf bytes.Buffer
+	is.PrintFrequencyDistribution(&buf)
+	if !strings.Contains(buf.String(), "[") {
+		t.Errorf("Observed ranges weren't printed:\n%s", buf.String())
+	}
+
+	b := NewAccumulatorWithOptions(WithBucketRange(), WithBuckets(2))
+	for _, v := range []int64{0, 3, 1, 7, 6, 9} {
+		b.Add(v)
+	}
+	is = b.GetStats()
+	if actual, correct := is.BucketMin, []int64{0, 6}; !equalInt64s(actual, correct) {
+		t.Errorf("BucketMin: %v != %v", actual, correct)
+	}
+	if actual, correct := is.BucketMax, []int64{3, 9}; !equalInt64s(actual, correct) {
+		t.Errorf("BucketMax: %v != %v", actual, correct)
+	}
+
+	if NewAccumulator(10, 2).GetStats().BucketMin != nil {
+		t.Errorf("Bucket ranges should be off by default")
+	}
+}
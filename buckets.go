@@ -0,0 +1,140 @@
+package cruncher
+
+import (
+	"fmt"
+	"math"
+)
+
+// Bucket describes a range of values in the frequency distribution
+type Bucket struct {
+	// LowerBound is the smallest value within the bucket
+	LowerBound int64
+	// UpperBound is the largest value within the bucket
+	UpperBound int64
+	// Count is the number of values within the bucket
+	Count int64
+	// Fraction is Count relative to the total number of values
+	Fraction float64
+	// IsOutlier is set for the values before or after the distribution
+	IsOutlier bool
+}
+
+// Buckets returns the frequency distribution in value order. When there are
+// outliers they're included as the first and last buckets, ranging from Min
+// and to Max respectively. The bounds of consecutive buckets are contiguous.
+func (is IntStats) Buckets() []Bucket {
+	buckets := make([]Bucket, 0, len(is.FrequencyDistribution)+2)
+	if is.OutlierBefore > 0 {
+		lower, _ := is.bucketBounds(0)
+		buckets = append(buckets, is.bucket(is.Min, lower-1, is.OutlierBefore, true))
+	}
+	for i, count := range is.FrequencyDistribution {
+		lower, upper := is.bucketBounds(i)
+		buckets = append(buckets, is.bucket(lower, upper, count, false))
+	}
+	if is.OutlierAfter > 0 {
+		_, upper := is.bucketBounds(len(is.FrequencyDistribution) - 1)
+		buckets = append(buckets, is.bucket(upper+1, is.Max, is.OutlierAfter, true))
+	}
+	return buckets
+}
+
+func (is IntStats) bucket(lower, upper, count int64, outlier bool) Bucket {
+	return Bucket{LowerBound: lower, UpperBound: upper, Count: count, IsOutlier: outlier, Fraction: is.fraction(count)}
+}
+
+// fraction returns count relative to Count, it's 0 when there are no values
+func (is IntStats) fraction(count int64) float64 {
+	if is.Count == 0 {
+		return 0
+	}
+	return float64(count) / float64(is.Count)
+}
+
+// DistributionFractions returns the fraction of the values within each
+// bucket of Buckets, so the outliers are leading or trailing entries when
+// there are any. The fractions sum to 1 unless there are no values.
+func (is IntStats) DistributionFractions() []float64 {
+	buckets := is.Buckets()
+	fractions := make([]float64, len(buckets))
+	for i, b := range buckets {
+		fractions[i] = b.Fraction
+	}
+	return fractions
+}
+
+// bucketOffset returns the index of the bucket containing value, which is
+// out of the bounds of FrequencyDistribution for outliers
+func (is IntStats) bucketOffset(value int64) int {
+	scaled := is.scaled(value)
+	if scaled < is.FrequencyDistributionStartingValue {
+		return -1
+	}
+	size := uint64(is.BucketSize)
+	if is.BucketSize < 1 {
+		size = 1
+	}
+	offset := span(is.FrequencyDistributionStartingValue, scaled) / size
+	if offset > math.MaxInt32 {
+		// Far beyond any distribution
+		return math.MaxInt32
+	}
+	return int(offset)
+}
+
+// BucketCountFor returns the number of values in the same bucket as value
+// along with the bucket's bounds. Values outside of the distribution return
+// the outliers before or after the distribution.
+func (is IntStats) BucketCountFor(value int64) (count int64, lower, upper int64) {
+	if len(is.FrequencyDistribution) == 0 {
+		return 0, 0, 0
+	}
+	offset := is.bucketOffset(value)
+	switch {
+	case offset < 0:
+		lower, _ = is.bucketBounds(0)
+		return is.OutlierBefore, is.Min, lower - 1
+	case offset >= len(is.FrequencyDistribution):
+		_, upper = is.bucketBounds(len(is.FrequencyDistribution) - 1)
+		return is.OutlierAfter, upper + 1, is.Max
+	}
+	lower, upper = is.bucketBounds(offset)
+	return is.FrequencyDistribution[offset], lower, upper
+}
+
+// BucketsForWidth returns the number of buckets, each width values wide,
+// needed to cover every value from min to max inclusive. That's
+// ceil((max - min + 1) / width), so it's 1 whenever width is larger than the
+// range. An error is returned if width isn't positive, max < min or the
+// number of buckets doesn't fit in an int.
+func BucketsForWidth(min, max, width int64) (int, error) {
+	if width <= 0 {
+		return 0, fmt.Errorf("%w: bucket width %d isn't positive", ErrInvalid, width)
+	}
+	if max < min {
+		return 0, fmt.Errorf("%w: max %d is less than min %d", ErrInvalid, max, min)
+	}
+	// Unsigned arithmetic avoids overflowing when the range exceeds MaxInt64
+	buckets := (uint64(max) - uint64(min)) / uint64(width)
+	if buckets >= math.MaxInt {
+		return 0, fmt.Errorf("%w: %d - %d needs more than %d buckets of %d", ErrInvalid, min, max, math.MaxInt, width)
+	}
+	return int(buckets + 1), nil
+}
+
+// ModalBucket returns the densest bucket of the frequency distribution, the
+// lowest bucket wins ties. Outliers aren't considered. index is -1 when
+// there's no distribution.
+func (is IntStats) ModalBucket() (index int, lower, upper, count int64) {
+	index = -1
+	for i, c := range is.FrequencyDistribution {
+		if index < 0 || c > count {
+			index, count = i, c
+		}
+	}
+	if index < 0 {
+		return index, 0, 0, 0
+	}
+	lower, upper = is.bucketBounds(index)
+	return index, lower, upper, count
+}
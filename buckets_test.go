@@ -0,0 +1,234 @@
+package cruncher
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBuckets(t *testing.T) {
+	is := IntStats{
+		Min:                                -500,
+		Max:                                5000,
+		Count:                              102,
+		BucketSize:                         100,
+		FrequencyDistributionStartingValue: 3,
+		FrequencyDistribution:              []int64{10, 12, 8, 0, 10, 10, 15, 5, 20, 10},
+		OutlierBefore:                      1,
+		OutlierAfter:                       1,
+	}
+	buckets := is.Buckets()
+	if actual, correct := len(buckets), len(is.FrequencyDistribution)+2; actual != correct {
+		t.Fatalf("Buckets: %d != %d", actual, correct)
+	}
+	if !buckets[0].IsOutlier || !buckets[len(buckets)-1].IsOutlier {
+		t.Errorf("First and last buckets should be outliers")
+	}
+	if actual, correct := buckets[0].LowerBound, is.Min; actual != correct {
+		t.Errorf("Lower bound: %d != %d", actual, correct)
+	}
+	if actual, correct := buckets[len(buckets)-1].UpperBound, is.Max; actual != correct {
+		t.Errorf("Upper bound: %d != %d", actual, correct)
+	}
+	var total int64
+	var fraction float64
+	for i, b := range buckets {
+		total += b.Count
+		fraction += b.Fraction
+		if b.UpperBound < b.LowerBound {
+			t.Errorf("Bucket %d bounds are reversed %d - %d", i, b.LowerBound, b.UpperBound)
+		}
+		if i > 0 && b.LowerBound != buckets[i-1].UpperBound+1 {
+			t.Errorf("Bucket %d isn't contiguous %d != %d", i, b.LowerBound, buckets[i-1].UpperBound+1)
+		}
+		if i > 0 && i < len(buckets)-1 && b.IsOutlier {
+			t.Errorf("Bucket %d shouldn't be an outlier", i)
+		}
+	}
+	if actual, correct := total, is.Count; actual != correct {
+		t.Errorf("Total: %d != %d", actual, correct)
+	}
+	if fraction < 0.999999 || fraction > 1.000001 {
+		t.Errorf("Fractions should sum to 1 but was %f", fraction)
+	}
+}
+
+func TestBucketCountFor(t *testing.T) {
+	is := IntStats{
+		Min:                                -500,
+		Max:                                5000,
+		Count:                              43,
+		BucketSize:                         10,
+		FrequencyDistributionStartingValue: 0,
+		FrequencyDistribution:              []int64{10, 20, 5},
+		OutlierBefore:                      3,
+		OutlierAfter:                       5,
+	}
+	for _, test := range []struct {
+		value, count, lower, upper int64
+	}{
+		{0, 10, 0, 9},
+		{9, 10, 0, 9},
+		{15, 20, 10, 19},
+		{29, 5, 20, 29},
+		{-1, 3, -500, -1},
+		{-500, 3, -500, -1},
+		{30, 5, 30, 5000},
+		{4000, 5, 30, 5000},
+	} {
+		count, lower, upper := is.BucketCountFor(test.value)
+		if count != test.count || lower != test.lower || upper != test.upper {
+			t.Errorf("BucketCountFor(%d): %d [%d - %d] != %d [%d - %d]",
+				test.value, count, lower, upper, test.count, test.lower, test.upper)
+		}
+	}
+	if count, _, _ := (IntStats{}).BucketCountFor(5); count != 0 {
+		t.Errorf("Empty distribution count: %d", count)
+	}
+}
+
+func TestBucketsForWidth(t *testing.T) {
+	for _, c := range []struct{ min, max, width int64 }{
+		{0, 99, 10}, {0, 100, 10}, {-50, 49, 7}, {5, 5, 3}, {0, 10, 1000},
+	} {
+		actual, err := BucketsForWidth(c.min, c.max, c.width)
+		if err != nil {
+			t.Fatalf("BucketsForWidth(%d, %d, %d): %v", c.min, c.max, c.width, err)
+		}
+		correct := int(math.Ceil(float64(c.max-c.min+1) / float64(c.width)))
+		if actual != correct {
+			t.Errorf("BucketsForWidth(%d, %d, %d): %d != %d", c.min, c.max, c.width, actual, correct)
+		}
+	}
+	if _, err := BucketsForWidth(0, 10, 0); err == nil {
+		t.Errorf("Zero width should be an error")
+	}
+	if actual, _ := BucketsForWidth(math.MinInt64, math.MaxInt64, math.MaxInt64); actual != 3 {
+		t.Errorf("Full range: %d != 3", actual)
+	}
+	if _, err := BucketsForWidth(math.MinInt64, math.MaxInt64, 1); err == nil {
+		t.Errorf("Too many buckets should be an error")
+	}
+
+	a := NewAccumulatorWithOptions(WithBucketWidth(10))
+	for i := int64(3); i <= 125; i++ {
+		a.Add(i)
+	}
+	is := a.GetStats()
+	if actual, correct := is.BucketSize, int64(10); actual != correct {
+		t.Errorf("BucketSize: %d != %d", actual, correct)
+	}
+	if actual, correct := len(is.FrequencyDistribution), 13; actual != correct {
+		t.Errorf("Buckets: %d != %d", actual, correct)
+	}
+	if actual, correct := is.FrequencyDistribution[12], int64(3); actual != correct {
+		t.Errorf("Last bucket: %d != %d", actual, correct)
+	}
+}
+
+func TestModalBucket(t *testing.T) {
+	is := IntStats{
+		BucketSize:                         10,
+		FrequencyDistributionStartingValue: -20,
+		FrequencyDistribution:              []int64{3, 8, 25, 9, 25, 1},
+		OutlierAfter:                       100,
+	}
+	index, lower, upper, count := is.ModalBucket()
+	if index != 2 || lower != 0 || upper != 9 || count != 25 {
+		t.Errorf("ModalBucket: %d [%d - %d] %d", index, lower, upper, count)
+	}
+	if index, _, _, count := (IntStats{}).ModalBucket(); index != -1 || count != 0 {
+		t.Errorf("Empty ModalBucket: %d %d", index, count)
+	}
+}
+
+func TestDistributionFractions(t *testing.T) {
+	is := IntStats{
+		Min:                                -100,
+		Max:                                100,
+		Count:                              40,
+		BucketSize:                         10,
+		FrequencyDistributionStartingValue: 0,
+		FrequencyDistribution:              []int64{3, 0, 17, 10},
+		OutlierBefore:                      7,
+		OutlierAfter:                       3,
+	}
+	fractions := is.DistributionFractions()
+	if actual, correct := len(fractions), len(is.FrequencyDistribution)+2; actual != correct {
+		t.Fatalf("Fractions: %d != %d", actual, correct)
+	}
+	var sum float64
+	for _, f := range fractions {
+		sum += f
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("Sum: %f != 1", sum)
+	}
+	if actual, correct := fractions[0], 7.0/40; actual != correct {
+		t.Errorf("Outliers before: %f != %f", actual, correct)
+	}
+	for _, f := range (IntStats{FrequencyDistribution: []int64{0}}).DistributionFractions() {
+		if f != 0 {
+			t.Errorf("Empty fraction: %f", f)
+		}
+	}
+}
+
+func TestBucketWidthWideRange(t *testing.T) {
+	for _, values := range [][]int64{{0, 1 << 40}, {math.MinInt64, math.MaxInt64}} {
+		a := NewAccumulatorWithOptions(WithBucketWidth(1))
+		for _, v := range values {
+			a.Add(v)
+		}
+		is := a.GetStats()
+		if actual, correct := len(is.FrequencyDistribution), MaxBuckets; actual != correct {
+			t.Errorf("Buckets for %d - %d: %d != %d", values[0], values[1], actual, correct)
+		}
+		if err := a.Validate(); err != nil {
+			t.Error(err)
+		}
+		if _, upper := is.bucketBounds(len(is.FrequencyDistribution) - 1); upper < values[1] {
+			t.Errorf("Distribution ends at %d before %d", upper, values[1])
+		}
+	}
+}
+
+func TestExtremeRange(t *testing.T) {
+	a := NewAccumulator(100, 4)
+	for _, v := range []int64{math.MinInt64, -1, 0, math.MaxInt64, math.MinInt64 + 1, math.MaxInt64 - 1} {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	if actual, correct := is.Range(), uint64(math.MaxUint64); actual != correct {
+		t.Errorf("Range: %d != %d", actual, correct)
+	}
+	if actual, correct := is.BucketSize, int64(1)<<62; actual != correct {
+		t.Errorf("BucketSize: %d != %d", actual, correct)
+	}
+	for i, correct := range []int64{2, 1, 1, 2} {
+		if actual := is.FrequencyDistribution[i]; actual != correct {
+			t.Errorf("Bucket %d: %d != %d", i, actual, correct)
+		}
+	}
+	if is.OutlierBefore != 0 || is.OutlierAfter != 0 {
+		t.Errorf("Outliers: %d, %d", is.OutlierBefore, is.OutlierAfter)
+	}
+	buckets := is.Buckets()
+	if lower, upper := buckets[0].LowerBound, buckets[3].UpperBound; lower != math.MinInt64 || upper != math.MaxInt64 {
+		t.Errorf("Bounds: %d - %d", lower, upper)
+	}
+	if count, lower, upper := is.BucketCountFor(-1); count != 1 || lower != -1<<62 || upper != -1 {
+		t.Errorf("BucketCountFor(-1): %d in %d - %d", count, lower, upper)
+	}
+	if err := a.Validate(); err != nil {
+		t.Error(err)
+	}
+	if actual := is.PercentileRank(-1); actual < 0.4 || actual > 0.6 {
+		t.Errorf("PercentileRank(-1): %f", actual)
+	}
+	if actual := is.PercentileFromDistribution(0.5); actual < -1<<62 || actual >= 1<<62 {
+		t.Errorf("PercentileFromDistribution(0.5): %d", actual)
+	}
+	if actual, correct := (IntStats{Min: -5, Max: 5}).Range(), uint64(10); actual != correct {
+		t.Errorf("Range: %d != %d", actual, correct)
+	}
+}
@@ -0,0 +1,87 @@
+package cruncher
+
+import "math"
+
+// coarsens reports whether the frequency distribution is widened as the
+// outliers accumulate, see WithCoarsening
+func (a *Accumulator) coarsens() bool {
+	return a.coarsening > 0 && a.scale == LinearScale
+}
+
+// coarsenIfNeeded widens the frequency distribution once the outliers exceed
+// the coarsening fraction of Count
+func (a *Accumulator) coarsenIfNeeded() {
+	is := &a.intStats
+	if !a.coarsens() || len(is.FrequencyDistribution) == 0 ||
+		float64(is.OutlierBefore+is.OutlierAfter) <= a.coarsening*float64(is.Count) {
+		return
+	}
+	a.coarsen()
+}
+
+// coarsen halves the resolution of the frequency distribution, by merging
+// pairs of adjacent buckets, until it covers Min to Max. The retained
+// outliers are then placed in the widened buckets.
+func (a *Accumulator) coarsen() {
+	is := &a.intStats
+	for _, v := range a.outliers {
+		if v < is.FrequencyDistributionStartingValue {
+			is.OutlierBefore--
+		} else {
+			is.OutlierAfter--
+		}
+	}
+	n := int64(len(is.FrequencyDistribution))
+	for {
+		start, size := is.FrequencyDistributionStartingValue, is.BucketSize
+		if is.Min >= start && (uint64(is.Max)-uint64(start))/uint64(size) < uint64(n) {
+			break
+		}
+		if size > math.MaxInt64/2 {
+			break
+		}
+		// Shift the start down by whole buckets, so each bucket still falls
+		// within a single wider bucket, when there are values below it
+		var k int64
+		if is.Min < start {
+			below := uint64(start) - uint64(is.Min)
+			// Flipping the sign bit gives the distance from MinInt64
+			if room := uint64(start) ^ (1 << 63); room < below {
+				below = room
+			}
+			k = int64((below + uint64(size) - 1) / uint64(size))
+			if k > n {
+				k = n
+			}
+		}
+		merged := make([]int64, n)
+		var bucketMin, bucketMax []int64
+		if is.BucketMin != nil {
+			bucketMin, bucketMax = make([]int64, n), make([]int64, n)
+		}
+		for i, count := range is.FrequencyDistribution {
+			if count == 0 {
+				continue
+			}
+			j := (int64(i) + k) / 2
+			if bucketMin != nil {
+				if merged[j] == 0 || is.BucketMin[i] < bucketMin[j] {
+					bucketMin[j] = is.BucketMin[i]
+				}
+				if merged[j] == 0 || is.BucketMax[i] > bucketMax[j] {
+					bucketMax[j] = is.BucketMax[i]
+				}
+			}
+			merged[j] += count
+		}
+		is.FrequencyDistribution = merged
+		is.BucketMin, is.BucketMax = bucketMin, bucketMax
+		is.FrequencyDistributionStartingValue = start - k*size
+		is.BucketSize = 2 * size
+	}
+	outliers := a.outliers
+	a.outliers = nil
+	for _, v := range outliers {
+		a.incrementFrequencyDistribution(v)
+	}
+}
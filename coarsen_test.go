@@ -0,0 +1,72 @@
+package cruncher
+
+import "testing"
+
+func TestCoarsening(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithWindow(100), WithBuckets(10), WithCoarsening(0.05), WithBucketRange())
+	var initial int64
+	for i := int64(0); i < 100000; i++ {
+		// Slowly widen the range in both directions
+		v := i / 2
+		if i%2 == 1 {
+			v = -v / 4
+		}
+		a.Add(v)
+		// Lay out the distribution over the first few values
+		if i == 50 {
+			a.Summarize()
+			initial = a.intStats.BucketSize
+		}
+		is := &a.intStats
+		if float64(is.OutlierBefore+is.OutlierAfter) > 0.05*float64(is.Count) {
+			t.Fatalf("Outliers %d + %d exceed 5%% of %d", is.OutlierBefore, is.OutlierAfter, is.Count)
+		}
+	}
+	is := a.GetStats()
+	if actual, correct := len(is.FrequencyDistribution), 10; actual != correct {
+		t.Errorf("Buckets: %d != %d", actual, correct)
+	}
+	// Each coarsening doubles the size
+	if d := is.BucketSize / initial; d <= 1 || is.BucketSize%initial != 0 || d&(d-1) != 0 {
+		t.Errorf("BucketSize %d isn't %d doubled", is.BucketSize, initial)
+	}
+	total := is.OutlierBefore + is.OutlierAfter
+	for i, count := range is.FrequencyDistribution {
+		total += count
+		lower := is.FrequencyDistributionStartingValue + int64(i)*is.BucketSize
+		if count > 0 && (is.BucketMin[i] < lower || is.BucketMax[i] >= lower+is.BucketSize) {
+			t.Errorf("Bucket %d range [%d - %d] outside [%d - %d)", i, is.BucketMin[i], is.BucketMax[i], lower, lower+is.BucketSize)
+		}
+	}
+	if actual, correct := total, is.Count; actual != correct {
+		t.Errorf("Total: %d != %d", actual, correct)
+	}
+	if err := a.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCoarseningSnapshotsBeforeWindow(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithWindow(100), WithDistributionStart(50), WithCoarsening(0.5))
+	for i := int64(0); i < 200; i++ {
+		v := 50 + i
+		if i%10 == 0 {
+			v = i / 10
+		}
+		a.Add(v)
+		is := a.GetStats()
+		if i >= 100 {
+			continue
+		}
+		if actual, correct := int64(len(a.outliers)), is.OutlierBefore+is.OutlierAfter; actual != correct {
+			t.Fatalf("Retained outliers after %d values: %d != %d", i+1, actual, correct)
+		}
+	}
+	is := a.GetStats()
+	if is.OutlierBefore < 0 || is.OutlierAfter < 0 {
+		t.Errorf("Outliers: %d, %d", is.OutlierBefore, is.OutlierAfter)
+	}
+	if err := a.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -45,34 +45,101 @@ package cruncher
 
 import (
 	"container/heap"
+	"context"
 	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"sort"
+	"strings"
+	"time"
 )
 
 const (
 	// InitialRemedianSize is the number of entries pre-allocated for maintaining
 	// the median
 	InitialRemedianSize = 4
+	// DefaultApproximationWindow is the approximation window used when
+	// none is provided to NewAccumulatorWithOptions
+	DefaultApproximationWindow = 1000
+	// DefaultBuckets is the number of buckets in the frequency distribution
+	// when none is provided to NewAccumulatorWithOptions
+	DefaultBuckets = 10
+	// MaxBuckets limits the number of buckets in the frequency distribution,
+	// wider buckets are used when more would be needed
+	MaxBuckets = 1 << 20
 )
 
 // IntStats contains all the stats accumulated. It's best to
 // maintain references only to the IntStats once the accumulation is
 // complete and remove references to Accumulator.
+//
+// Every method may be called when no values were added, Count is 0: values
+// computed from the data are 0, lists are empty, methods reporting whether
+// they succeeded return false and ModalBucket's index is -1. Only ratios
+// that are undefined without data, CoefficientOfVariation, Gini and the
+// Percent of a Diff, are NaN.
 type IntStats struct {
+	// Name optionally identifies the data set in printed reports
+	Name string
 	// Smallest valued added
 	Min int64
 	// Largest value added
 	Max int64
+	// SecondMin and SecondMax are the second smallest and second largest
+	// values added, they equal Min and Max when the extreme was added more
+	// than once or there's only one value. A large gap from Min or Max
+	// suggests a single erroneous extreme.
+	SecondMin int64
+	SecondMax int64
+	// IsMonotonicIncreasing and IsMonotonicDecreasing are set when each value
+	// was added in non-decreasing or non-increasing order respectively, so
+	// both are set for a single value. Sorted input may indicate mis-fed data.
+	IsMonotonicIncreasing bool
+	IsMonotonicDecreasing bool
 	// Number of entries added
 	Count int64
-	// Mean is computed using a total / count it may be subject to overflow
+	// NegativeCount, ZeroCount and PositiveCount tally the values added by
+	// sign, they sum to Count
+	NegativeCount int64
+	ZeroCount     int64
+	PositiveCount int64
+	// Skipped is the number of values ignored because they were registered
+	// WithSkipValue, they aren't included in any of the other statistics
+	Skipped int64
+	// SampleRate is the fraction of the values included in the stats set
+	// WithSampleRate, it's 0 when every value is included. See
+	// EstimatedCount and EstimatedSum.
+	SampleRate float64 `json:",omitempty"`
+	// NonFinite is the number of NaN and ±Inf values given to a
+	// FloatAccumulator, see NonFinitePolicy
+	NonFinite int64 `json:",omitempty"`
+	// Sum is the total of all the values added. It's maintained with 128 bits
+	// so it isn't subject to overflow
+	Sum *big.Int
+	// Mean is computed using Sum / Count
 	Mean float64
-	// Median is an approximation using the Remedian technicque
+	// Variance is the population variance of the values added
+	Variance float64
+	// StdDev is the population standard deviation of the values added
+	StdDev float64
+	// DecayedMean is an exponentially weighted mean favoring recent values
+	// and DecayedCount is the effective number of values it represents.
+	// They're only computed when the Accumulator is created WithDecay.
+	DecayedMean  float64
+	DecayedCount float64
+	// Median is an approximation using the Remedian technicque, for an even
+	// number of values it's the lower of the two middle values
 	Median int64
 	// FrequencyDistribution contains the count of occurances within a bucket
 	FrequencyDistribution []int64
+	// BucketMin and BucketMax contain the smallest and largest value observed
+	// within each bucket, they're zero for empty buckets. They're only
+	// maintained when the Accumulator is created WithBucketRange.
+	BucketMin []int64
+	BucketMax []int64
+	// Scale is how values are grouped into buckets, LinearScale by default
+	Scale Scale
 	// BucketSize contains the range of values within a bucket
 	BucketSize int64
 	// FrequencyDistributionStartingValue is the starting value for the
@@ -82,18 +149,110 @@ type IntStats struct {
 	OutlierBefore int64
 	// OutlierAfter is the number of occurances higher than the largest bucket
 	OutlierAfter int64
-	// Frequency
+	// PoorBucketing is set when the outliers exceed OutlierWarnFraction of
+	// Count, suggesting the window or distribution start were poorly chosen
+	PoorBucketing bool
+	// ValueFrequency is the approximate number of times each of the most
+	// frequent values was added. At most approximationWindow values are tracked.
 	ValueFrequency map[int64]int64
+	// ValueFirstSeen orders the values of ValueFrequency by when they were
+	// first added, it's only recorded WithInsertionOrderTies and then
+	// breaks ties between equally frequent terms
+	ValueFirstSeen map[int64]int64 `json:",omitempty"`
+	// TermsEvicted is the number of times a value stopped being tracked in
+	// ValueFrequency to make room for another, the frequencies are only
+	// exact while it's 0
+	TermsEvicted int64 `json:",omitempty"`
+	// WeightedFrequency is the approximate total weight given to each of the
+	// values with the largest weight by AddWithWeight
+	WeightedFrequency map[int64]int64
+	// DistinctEstimate is the approximate number of distinct values added,
+	// it's only computed when the Accumulator is created WithDistinctEstimate
+	DistinctEstimate uint64
+	// P2Quantiles are running estimates of the quantiles requested
+	// WithP2Quantiles, in the order requested
+	P2Quantiles []Quantile
+	// Centroids summarize the values in a t-digest, ordered by their mean.
+	// They're only maintained when the Accumulator is created WithTDigest.
+	Centroids []Centroid
+	// Sample is a sorted, uniformly random, sample of the values added when
+	// the Accumulator is created WithReservoir. In exact mode it holds every
+	// value added, so json.Marshal carries the whole data set and MergeStats
+	// combines the values when both stats hold all of theirs.
+	Sample []int64
+	// ReportOptions control how Print renders the stats
+	ReportOptions `json:"-"`
 }
 
 // Accumulator maintains the transient state collected when accomulating
 // statistics on a set of data. The results are available GetStats
 type Accumulator struct {
-	intStats           IntStats
-	remedians          [][]int64
-	total              int64
+	// Name optionally identifies the data set, it's copied to IntStats
+	// when the data is summarized
+	Name string
+	// ReportOptions control how Print renders the stats, they're copied to
+	// IntStats when the data is summarized
+	ReportOptions
+
+	intStats  IntStats
+	remedians [][]int64
+	// frequency is nil when the Accumulator is created WithoutTermFrequency
+	frequency *spaceSaving
+	// dense counts the values in the range given WithDenseFrequency
+	dense *denseFrequency
+	// weighted is allocated by the first AddWithWeight
+	weighted  *spaceSaving
+	distinct  *hyperLogLog
+	reservoir *reservoir
+	decay     *decay
+	p2        []*p2Quantile
+	digest    *tDigest
+	total     int128
+	// runningMean and m2 maintain the variance using Welford's algorithm
+	runningMean        float64
+	m2                 float64
 	appoximationWindow int
 	buckets            int
+	// distributionStart pins the start of the frequency distribution when
+	// pinnedStart is set
+	distributionStart    int64
+	pinnedStart          bool
+	withoutTermFrequency bool
+	bucketRange          bool
+	scale                Scale
+	bucketWidth          int64
+	quantizeStep         int64
+	// coarsening is the fraction of Count the outliers may reach before the
+	// frequency distribution is widened, the outlier values are retained in
+	// outliers until then
+	coarsening float64
+	outliers   []int64
+	// domain limits the values AddChecked accepts to domainMin to domainMax
+	domain               bool
+	domainMin, domainMax int64
+	// skip holds the values registered WithSkipValue
+	skip map[int64]bool
+	// exact retains every value in values rather than using the Remedian
+	exact             bool
+	medianEvenAverage bool
+	// nonFinitePolicy is used by FloatAccumulator
+	nonFinitePolicy NonFinitePolicy
+	// sampler is set WithSampleRate
+	sampler *sampler
+	// previous is the last value added
+	previous int64
+	// frequencyCapacity and eviction bound the term frequency
+	frequencyCapacity int
+	eviction          EvictionPolicy
+	// insertionOrder records when terms are first seen to break ties
+	insertionOrder bool
+	// expectedCardinality is the hint given WithExpectedCardinality
+	expectedCardinality int
+	values              []int64
+	interval            int64
+	onInterval          func(IntStats)
+	// onRemedianFlush is called as each Remedian block collapses
+	onRemedianFlush func(level int, min, max, median int64)
 }
 
 // NewAccumulator allocates an accumulator that collects statistics on data added.
@@ -102,34 +261,39 @@ type Accumulator struct {
 // value is also used to compute the median. Larger values require more
 // memory but may be required if data values are not
 // randomly distributed.
-// buckets are the number of groups in the frequency distribution
+// buckets are the number of groups in the frequency distribution. A window
+// or number of buckets less than 1 is treated as 1.
 func NewAccumulator(appoximationWindow, buckets int) *Accumulator {
-	a := new(Accumulator)
-	a.appoximationWindow = appoximationWindow
-	a.remedians = make([][]int64, 0, InitialRemedianSize)
-	a.buckets = buckets
-	return a
+	return NewAccumulatorWithOptions(WithWindow(appoximationWindow), WithBuckets(buckets))
 }
 
 // Add adds a value to the data set to be summarized. Add is typically a constant
 // time operation but may periodically include some iteration to update some
 // statistics.
 func (a *Accumulator) Add(value int64) {
-	// Adjust Min and Max
-	if a.intStats.Count == 0 {
-		a.intStats.Max = value
-		a.intStats.Min = value
-		a.intStats.ValueFrequency = make(map[int64]int64)
-	} else {
-		if a.intStats.Max < value {
-			a.intStats.Max = value
-		} else if a.intStats.Min > value {
-			a.intStats.Min = value
-		}
+	if a.skip[value] {
+		a.intStats.Skipped++
+		return
 	}
+	if a.sampler != nil && !a.sampler.include() {
+		return
+	}
+	a.updateExtremes(value)
+	a.updateMonotonic(value)
 	// Adjust Counts and Totals
 	a.intStats.Count++
-	a.total += value
+	switch {
+	case value < 0:
+		a.intStats.NegativeCount++
+	case value == 0:
+		a.intStats.ZeroCount++
+	default:
+		a.intStats.PositiveCount++
+	}
+	a.total.add(value)
+	delta := float64(value) - a.runningMean
+	a.runningMean += delta / float64(a.intStats.Count)
+	a.m2 += delta * (float64(value) - a.runningMean)
 
 	// Update frequency distribution
 	count := a.intStats.Count
@@ -137,42 +301,184 @@ func (a *Accumulator) Add(value int64) {
 	// One time configure Frequency Distribution
 	if len(a.intStats.FrequencyDistribution) > 0 {
 		a.incrementFrequencyDistribution(value)
+		a.coarsenIfNeeded()
 	} else if count == int64(a.appoximationWindow) {
 		a.initializeFrequencyDistribution()
+		// value isn't pending yet, it's pushed to the Remedian below
+		a.incrementFrequencyDistribution(value)
 	}
 	// Must do this last so the full set of values is available
-	a.pushMedianValue(0, value)
+	if a.exact {
+		a.values = append(a.values, value)
+	} else {
+		a.pushMedianValue(0, value)
+	}
 
-	// Count frequencies but don't count more than a.appoximationWindow
-	valueCount, present := a.intStats.ValueFrequency[value]
-	if present {
-		a.intStats.ValueFrequency[value] = valueCount + 1
-	} else if len(a.intStats.ValueFrequency) < a.appoximationWindow {
-		a.intStats.ValueFrequency[value] = 1
+	if a.frequency != nil {
+		term := quantize(value, a.quantizeStep)
+		if a.dense == nil || !a.dense.add(term) {
+			a.frequency.add(term)
+		}
+	}
+	if a.distinct != nil {
+		a.distinct.add(value)
+	}
+	if a.reservoir != nil {
+		a.reservoir.add(value)
+	}
+	if a.decay != nil {
+		a.decay.add(value)
+	}
+	for _, q := range a.p2 {
+		q.add(value)
+	}
+	if a.digest != nil {
+		a.digest.add(value)
+	}
+	if a.onInterval != nil && a.intStats.Count%a.interval == 0 {
+		a.onInterval(a.Snapshot())
 	}
 }
 
 func (a *Accumulator) initializeFrequencyDistribution() {
 	a.intStats.OutlierAfter = 0
 	a.intStats.OutlierBefore = 0
-	a.intStats.FrequencyDistribution = make([]int64, a.buckets)
-	a.intStats.FrequencyDistributionStartingValue = a.intStats.Min
-	diff := a.intStats.Max - a.intStats.Min
-	a.intStats.BucketSize = int64(math.Ceil(float64(diff+1) / float64(a.buckets)))
-	for _, v := range a.remedians[0] {
-		a.incrementFrequencyDistribution(int64(v))
+	// The pending values are counted again below, including the outliers
+	a.outliers = nil
+	a.intStats.Scale = a.scale
+	start := a.intStats.Min
+	if a.pinnedStart {
+		start = a.distributionStart
+	}
+	start = a.intStats.scaled(start)
+	a.intStats.FrequencyDistributionStartingValue = start
+	end := a.intStats.scaled(a.intStats.Max)
+	if end < start {
+		end = start
+	}
+	diff := span(start, end)
+	// Never use more buckets than there are distinct integers in the range,
+	// otherwise most of the buckets can never be filled. When Min == Max this
+	// collapses the distribution to a single bucket.
+	buckets := a.buckets
+	width := a.bucketWidth
+	if a.scale == SymLogScale {
+		width = 0
+	}
+	if width > 0 {
+		var err error
+		if buckets, err = BucketsForWidth(start, end, width); err != nil || buckets > MaxBuckets {
+			// The range is too wide for buckets of width
+			buckets, width = MaxBuckets, 0
+		}
+	}
+	if a.scale == SymLogScale {
+		buckets = int(diff + 1)
+	} else {
+		if buckets > MaxBuckets {
+			buckets = MaxBuckets
+		}
+		buckets = fitBuckets(diff, buckets)
+	}
+	a.intStats.FrequencyDistribution = make([]int64, buckets)
+	if a.bucketRange {
+		a.intStats.BucketMin = make([]int64, buckets)
+		a.intStats.BucketMax = make([]int64, buckets)
+	}
+	a.intStats.BucketSize = bucketSizeFor(diff, buckets)
+	if width > 0 {
+		a.intStats.BucketSize = width
+	}
+	for _, v := range a.pending() {
+		a.incrementFrequencyDistribution(v)
+	}
+}
+
+// updateMonotonic tracks whether the values are added in order, it must be
+// called before Count is incremented
+func (a *Accumulator) updateMonotonic(value int64) {
+	if a.intStats.Count == 0 {
+		a.intStats.IsMonotonicIncreasing, a.intStats.IsMonotonicDecreasing = true, true
+	} else {
+		a.intStats.IsMonotonicIncreasing = a.intStats.IsMonotonicIncreasing && value >= a.previous
+		a.intStats.IsMonotonicDecreasing = a.intStats.IsMonotonicDecreasing && value <= a.previous
+	}
+	a.previous = value
+}
+
+// updateExtremes adjusts Min, Max, SecondMin and SecondMax for value
+func (a *Accumulator) updateExtremes(value int64) {
+	is := &a.intStats
+	switch {
+	case is.Count == 0:
+		is.Min, is.Max, is.SecondMin, is.SecondMax = value, value, value, value
+		return
+	case value < is.Min:
+		is.SecondMin, is.Min = is.Min, value
+	case is.Count == 1 || value < is.SecondMin:
+		is.SecondMin = value
+	}
+	switch {
+	case value > is.Max:
+		is.SecondMax, is.Max = is.Max, value
+	case is.Count == 1 || value > is.SecondMax:
+		is.SecondMax = value
+	}
+}
+
+// span returns hi - lo for lo <= hi. It's unsigned so it doesn't overflow
+// when the range exceeds MaxInt64, such as from MinInt64 to MaxInt64.
+func span(lo, hi int64) uint64 {
+	return uint64(hi) - uint64(lo)
+}
+
+// fitBuckets limits buckets to the diff+1 values in the range, otherwise
+// most of the buckets can never be filled. Buckets are added when the range
+// is too wide for a bucket size to represent.
+func fitBuckets(diff uint64, buckets int) int {
+	if uint64(buckets)-1 > diff {
+		return int(diff + 1)
+	}
+	for diff/uint64(buckets) >= math.MaxInt64 {
+		buckets++
+	}
+	return buckets
+}
+
+// bucketSizeFor returns the smallest bucket size for buckets to cover diff+1
+// values, ceil((diff+1)/buckets) computed without overflowing diff+1
+func bucketSizeFor(diff uint64, buckets int) int64 {
+	return int64(diff/uint64(buckets) + 1)
+}
+
+// pending returns the values added before the frequency distribution was
+// initialized
+func (a *Accumulator) pending() []int64 {
+	if a.exact {
+		return a.values
 	}
+	if len(a.remedians) == 0 {
+		return nil
+	}
+	return a.remedians[0]
 }
 
 func (a *Accumulator) incrementFrequencyDistribution(value int64) (offset int) {
 	// Update bucket value
-	offset = int(math.Floor((float64(value-a.intStats.FrequencyDistributionStartingValue) / float64(a.intStats.BucketSize))))
+	offset = a.intStats.bucketOffset(value)
 	// Handle out of bounds
+	outlier := offset < 0 || offset >= len(a.intStats.FrequencyDistribution)
+	if outlier && a.coarsens() {
+		a.outliers = append(a.outliers, value)
+	}
 	if offset < 0 {
 		a.intStats.OutlierBefore++
-	} else if offset >= len(a.intStats.FrequencyDistribution) {
+	} else if outlier {
 		a.intStats.OutlierAfter++
 	} else {
+		if a.intStats.BucketMin != nil {
+			a.updateBucketRange(offset, value)
+		}
 		// Increment bucket
 		a.intStats.FrequencyDistribution[offset]++
 	}
@@ -193,61 +499,287 @@ func (a *Accumulator) pushMedianValue(offset int, value int64) (computed bool, m
 	if medianLength := len(a.remedians[offset]); a.appoximationWindow < medianLength {
 		min, max, median = computeMedian(a.remedians[offset])
 		computed = true
+		if a.onRemedianFlush != nil {
+			a.onRemedianFlush(offset, min, max, median)
+		}
 		a.pushMedianValue(offset+1, median)
 		a.remedians[offset] = a.remedians[offset][:0]
 	}
 	return computed, min, max, median
 }
 
+// computeMedian returns the smallest, largest and median values, the lower
+// of the two middle values when there's an even number of them. values is
+// sorted in place so the caller's ordering is consumed, pushMedianValue
+// relies on this as the level is discarded once its median is computed.
+// Use medianOf when the ordering must be preserved.
 func computeMedian(values []int64) (min, max, median int64) {
 	sort.Sort(int64arr(values))
 	l := len(values)
-	return values[0], values[l-1], values[l/2]
+	return values[0], values[l-1], values[(l-1)/2]
+}
+
+// medianOf returns the median of values without reordering them
+func medianOf(values []int64) int64 {
+	_, _, median := computeMedian(append([]int64(nil), values...))
+	return median
+}
+
+// exactSmallMedian returns the exact median of a data set small enough to
+// fit within a single Remedian block, without reordering values. Like every
+// median it's the lower of the two middle values when there's an even
+// number of them, unless average is set, see middle.
+func (is IntStats) exactSmallMedian(values []int64, average bool) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Sort(int64arr(sorted))
+	return middle(sorted, average)
+}
+
+// middle returns the median of the sorted values. For an even number of
+// values it's the lower of the two middle values or, when average is set,
+// their mean rounded up.
+func middle(sorted []int64, average bool) int64 {
+	l := len(sorted)
+	lower := sorted[(l-1)/2]
+	if !average || l%2 == 1 {
+		return lower
+	}
+	// Unsigned arithmetic avoids overflowing when the values are far apart
+	diff := uint64(sorted[l/2]) - uint64(lower)
+	return lower + int64(diff/2+diff%2)
+}
+
+// sortChunk is the number of values sorted between cancellation checks
+const sortChunk = 1 << 16
+
+// sortedContext returns a sorted copy of values. The sort is done in chunks
+// that are merged together, checking ctx between each step.
+func sortedContext(ctx context.Context, values []int64) ([]int64, error) {
+	sorted := append([]int64(nil), values...)
+	for i := 0; i < len(sorted); i += sortChunk {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := i + sortChunk
+		if end > len(sorted) {
+			end = len(sorted)
+		}
+		sort.Sort(int64arr(sorted[i:end]))
+	}
+	buffer := make([]int64, len(sorted))
+	for width := sortChunk; width < len(sorted); width *= 2 {
+		for i := 0; i < len(sorted); i += 2 * width {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			mid, end := i+width, i+2*width
+			if mid > len(sorted) {
+				mid = len(sorted)
+			}
+			if end > len(sorted) {
+				end = len(sorted)
+			}
+			mergeSorted(buffer[i:end], sorted[i:mid], sorted[mid:end])
+		}
+		sorted, buffer = buffer, sorted
+	}
+	return sorted, nil
+}
+
+// mergeSorted merges the sorted slices a and b into dst
+func mergeSorted(dst, a, b []int64) {
+	i, j := 0, 0
+	for k := range dst {
+		if j >= len(b) || (i < len(a) && a[i] <= b[j]) {
+			dst[k] = a[i]
+			i++
+		} else {
+			dst[k] = b[j]
+			j++
+		}
+	}
 }
 
 // Summarize computes the frequency distribution and median
 // calculation on the data samples that haven't been summarized
 // yet.
 func (a *Accumulator) Summarize() {
-	if a.intStats.Count < int64(a.appoximationWindow) {
+	a.SummarizeContext(context.Background())
+}
+
+// SummarizeContext is Summarize that can be cancelled while sorting the
+// values in exact mode. On cancellation it returns ctx.Err() without
+// updating the statistics.
+func (a *Accumulator) SummarizeContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var sorted []int64
+	if a.exact {
+		var err error
+		if sorted, err = sortedContext(ctx, a.values); err != nil {
+			return err
+		}
+	}
+	if a.intStats.Count > 0 && a.intStats.Count < int64(a.appoximationWindow) {
 		a.initializeFrequencyDistribution()
 	}
-	a.intStats.Mean = float64(a.total) / float64(a.intStats.Count)
-	for i := len(a.remedians) - 1; i >= 0; i-- {
-		_, _, a.intStats.Median = computeMedian(a.remedians[i])
-		return
+	a.intStats.Name = a.Name
+	if a.frequency != nil {
+		a.intStats.ValueFrequency = a.frequency.frequencies()
+		a.intStats.TermsEvicted = a.frequency.evicted
+		if a.dense != nil {
+			a.dense.addTo(a.intStats.ValueFrequency)
+		}
+		if a.insertionOrder {
+			a.intStats.ValueFirstSeen = a.frequency.firstSeen()
+		}
+	}
+	if a.weighted != nil {
+		a.intStats.WeightedFrequency = a.weighted.frequencies()
+	}
+	if a.distinct != nil {
+		a.intStats.DistinctEstimate = a.distinct.estimate()
+	}
+	if a.reservoir != nil {
+		a.intStats.Sample = a.reservoir.sorted()
+	}
+	if a.digest != nil {
+		a.digest.compress()
+		a.intStats.Centroids = append([]Centroid(nil), a.digest.centroids...)
+	}
+	if a.p2 != nil {
+		a.intStats.P2Quantiles = make([]Quantile, len(a.p2))
+		for i, q := range a.p2 {
+			a.intStats.P2Quantiles[i] = Quantile{P: q.p, Value: q.estimate()}
+		}
+	}
+	if a.decay != nil {
+		a.intStats.DecayedMean = a.decay.mean()
+		a.intStats.DecayedCount = a.decay.count
 	}
+	a.intStats.ReportOptions = a.ReportOptions
+	outliers := a.intStats.OutlierBefore + a.intStats.OutlierAfter
+	a.intStats.PoorBucketing = a.OutlierWarnFraction > 0 &&
+		float64(outliers) > a.OutlierWarnFraction*float64(a.intStats.Count)
+	a.intStats.Sum = a.total.Big()
+	if a.intStats.Count > 0 {
+		a.intStats.Mean = a.total.Float64() / float64(a.intStats.Count)
+		a.intStats.Variance = a.m2 / float64(a.intStats.Count)
+		a.intStats.StdDev = math.Sqrt(a.intStats.Variance)
+	}
+	if a.exact && len(sorted) > 0 {
+		a.intStats.Sample = sorted
+		a.intStats.Median = middle(sorted, a.medianEvenAverage)
+		return nil
+	}
+	switch top := len(a.remedians) - 1; {
+	case top == 0:
+		// Until the first block is collapsed it holds every value
+		a.intStats.Median = a.intStats.exactSmallMedian(a.remedians[0], a.medianEvenAverage)
+	case top > 0:
+		// The highest level holds the medians of all the collapsed levels below it
+		a.intStats.Median = medianOf(a.remedians[top])
+	}
+	return nil
 }
 
-type pairHeap []Pair
+// pairHeap orders pairs from the lowest to the highest ranked
+type pairHeap struct {
+	pairs  PairList
+	before func(a, b Pair) bool
+}
 
-func (h pairHeap) Len() int           { return len(h) }
-func (h pairHeap) Less(i, j int) bool { return h[i].Frequency < h[j].Frequency }
-func (h pairHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *pairHeap) Len() int           { return len(h.pairs) }
+func (h *pairHeap) Less(i, j int) bool { return h.before(h.pairs[j], h.pairs[i]) }
+func (h *pairHeap) Swap(i, j int)      { h.pairs[i], h.pairs[j] = h.pairs[j], h.pairs[i] }
 
 func (h *pairHeap) Push(x interface{}) {
-	*h = append(*h, x.(Pair))
+	h.pairs = append(h.pairs, x.(Pair))
 }
 
 func (h *pairHeap) Pop() interface{} {
-	old := *h
+	old := h.pairs
 	n := len(old)
 	x := old[n-1]
-	*h = old[0 : n-1]
+	h.pairs = old[0 : n-1]
 	return x
 }
 
 // GetTermFrequency returns the most frequently used terms. This is an
-// Approximation. If the first term does not appear within the
-// first approximationWindow data set then it will be omitted from the results
+// approximation, once more than approximationWindow distinct values are
+// added the least frequent values are replaced and counts may be overstated.
+// The result is empty when the Accumulator is created WithoutTermFrequency.
 func (is IntStats) GetTermFrequency(topN int) PairList {
-	h := &pairHeap{}
-	heap.Init(h)
+	return topTerms(is.ValueFrequency, topN, nil, is.ranksBefore)
+}
+
+// Mode returns the most frequently added value, the smallest wins ties
+// unless ValueFirstSeen is recorded. It's an approximation like
+// GetTermFrequency, ok is false when there are no terms.
+func (is IntStats) Mode() (value int64, ok bool) {
+	top := is.GetTermFrequency(1)
+	if len(top) == 0 {
+		return 0, false
+	}
+	return top[0].Value, true
+}
+
+// Range returns Max - Min, it's 0 when there are no values. It's unsigned
+// as the range from MinInt64 to MaxInt64 exceeds MaxInt64.
+func (is IntStats) Range() uint64 {
+	return span(is.Min, is.Max)
+}
+
+// TopTermsInRange returns the most frequently used terms from lo to hi
+// inclusive, such as the most frequent values below the median.
+func (is IntStats) TopTermsInRange(lo, hi int64, topN int) PairList {
+	return topTerms(is.ValueFrequency, topN, func(v int64) bool {
+		return lo <= v && v <= hi
+	}, is.ranksBefore)
+}
+
+// AllTermFrequencies returns every tracked term ordered by descending
+// frequency, ties are broken as in GetTermFrequency.
+func (is IntStats) AllTermFrequencies() PairList {
+	pl := make(PairList, 0, len(is.ValueFrequency))
+	for v, f := range is.ValueFrequency {
+		pl = append(pl, Pair{v, f})
+	}
+	sort.Slice(pl, func(i, j int) bool { return is.ranksBefore(pl[i], pl[j]) })
+	return pl
+}
+
+// ranksBefore reports whether term a is more frequent than b. Equally
+// frequent terms are ordered by when they were first seen when
+// ValueFirstSeen is recorded, otherwise by value.
+func (is IntStats) ranksBefore(a, b Pair) bool {
+	if a.Frequency != b.Frequency {
+		return a.Frequency > b.Frequency
+	}
+	if is.ValueFirstSeen != nil {
+		if fa, fb := is.ValueFirstSeen[a.Value], is.ValueFirstSeen[b.Value]; fa != fb {
+			return fa < fb
+		}
+	}
+	return a.Value < b.Value
+}
+
+// topTerms returns the topN highest ranked terms, ordered by before, among
+// those accepted by keep. A nil keep accepts every term.
+func topTerms(frequency map[int64]int64, topN int, keep func(int64) bool, before func(a, b Pair) bool) PairList {
+	h := &pairHeap{before: before}
 	// Create heap of the topN most frequent terms
-	for k, f := range is.ValueFrequency {
+	for k, f := range frequency {
+		if keep != nil && !keep(k) {
+			continue
+		}
 		if h.Len() < topN {
 			heap.Push(h, Pair{k, f})
-		} else if (*h)[0].Frequency < f {
+		} else if before(Pair{k, f}, h.pairs[0]) {
 			heap.Pop(h)
 			heap.Push(h, Pair{k, f})
 		}
@@ -255,8 +787,7 @@ func (is IntStats) GetTermFrequency(topN int) PairList {
 	// Copy them to a list
 	pl := make(PairList, h.Len(), h.Len())
 	for i := h.Len() - 1; i >= 0; i-- {
-		pl[i] = (*h)[0]
-		heap.Pop(h)
+		pl[i] = heap.Pop(h).(Pair)
 	}
 	return pl
 }
@@ -270,12 +801,103 @@ type Pair struct {
 // PairList is an array of Pair's
 type PairList []Pair
 
+// SumOfSquares returns the sum of the squared values, it's recomputed from
+// the Variance and Mean so it's subject to floating point rounding
+func (is IntStats) SumOfSquares() float64 {
+	return float64(is.Count) * (is.Variance + is.Mean*is.Mean)
+}
+
+// CoefficientOfVariation returns StdDev relative to Mean, a scale free
+// measure of the variability for comparing data sets. It's negative when
+// the Mean is and NaN when the Mean is 0, including when there are no values.
+func (is IntStats) CoefficientOfVariation() float64 {
+	if is.Mean == 0 {
+		return math.NaN()
+	}
+	return is.StdDev / is.Mean
+}
+
+// Gini returns the Gini coefficient of the counts in the buckets of the
+// frequency distribution, how concentrated the values are. It's 0 when every
+// bucket holds the same number of values and approaches 1 as they all fall
+// in a single bucket, reaching (n-1)/n for n buckets. It's NaN when there
+// are no values.
+func (is IntStats) Gini() float64 {
+	counts := append([]int64(nil), is.FrequencyDistribution...)
+	sort.Slice(counts, func(i, j int) bool { return counts[i] < counts[j] })
+	var total, weighted float64
+	for i, c := range counts {
+		total += float64(c)
+		weighted += float64(i+1) * float64(c)
+	}
+	if total == 0 {
+		return math.NaN()
+	}
+	n := float64(len(counts))
+	return 2*weighted/(n*total) - (n+1)/n
+}
+
 // GetStats provides the current stats accumulated. If the data set continues to
 // accumulate the accumulator update the results however,
 // The copy returned will not be impacted.
 func (a *Accumulator) GetStats() IntStats {
 	a.Summarize()
-	return a.intStats
+	return a.intStats.clone()
+}
+
+// Snapshot provides the stats accumulated so far without finalizing the
+// accumulator. Unlike GetStats, a Snapshot taken before approximationWindow
+// values are added doesn't fix the frequency distribution's range.
+func (a *Accumulator) Snapshot() IntStats {
+	if a.intStats.Count == 0 {
+		return IntStats{Name: a.Name, ReportOptions: a.ReportOptions, Skipped: a.intStats.Skipped,
+			SampleRate: a.intStats.SampleRate, NonFinite: a.intStats.NonFinite}
+	}
+	c := *a
+	c.Summarize()
+	return c.intStats.clone()
+}
+
+// clone copies the stats so they're not affected by further accumulation
+func (is IntStats) clone() IntStats {
+	is.FrequencyDistribution = append([]int64(nil), is.FrequencyDistribution...)
+	if is.BucketMin != nil {
+		is.BucketMin = append([]int64(nil), is.BucketMin...)
+		is.BucketMax = append([]int64(nil), is.BucketMax...)
+	}
+	return is
+}
+
+// ReportOptions control how Print renders the summarized data
+type ReportOptions struct {
+	// SkipEmptyBuckets omits buckets that don't contain any values from the
+	// distribution, consecutive empty buckets are reported as a single line
+	SkipEmptyBuckets bool
+	// Verbose adds the Sum and sum of squares to the summary
+	Verbose bool
+	// MarkIQROutliers marks the buckets entirely outside of OutlierBounds
+	// with a "!!" when printing the distribution
+	MarkIQROutliers bool
+	// ShowCumulative adds the running total of the values, and its
+	// percentage, to each row of the distribution
+	ShowCumulative bool
+	// SortBucketsByCount prints the distribution from the fullest to the
+	// emptiest bucket rather than in value order
+	SortBucketsByCount bool
+	// Format selects how values are rendered, it defaults to FormatInt
+	Format Format
+	// Unit, such as "ms" or "bytes", is appended to printed values
+	Unit string
+	// Duration is the period of time the data covers, when it's set the
+	// summary includes the Rate and SumRate per second
+	Duration time.Duration
+	// OutlierWarnFraction flags the stats as PoorBucketing when the outliers
+	// exceed this fraction of Count, zero disables the warning
+	OutlierWarnFraction float64
+	// PercentDecimals is the number of decimal places percentages are
+	// printed with so rare buckets aren't rounded to 0%. Zero uses the
+	// default of 2.
+	PercentDecimals int
 }
 
 // Print an ascii formatted human readable version of the summarized data
@@ -286,12 +908,39 @@ func (a *Accumulator) Print(w io.Writer) {
 
 // Print outputs all the the acquired data about the accumulated values.
 func (is IntStats) Print(w io.Writer) {
-	is.PrintSummary(w)
-	fmt.Println()
-	is.PrintFrequencyDistribution(w)
-	fmt.Println()
-	fmt.Println()
-	is.PrintValueFrequency(w, 5)
+	is.PrintSections(w, SummarySection, DistributionSection, TopTermsSection)
+}
+
+// Section identifies a part of the printed report
+type Section int
+
+const (
+	// SummarySection is the output of PrintSummary
+	SummarySection Section = iota
+	// DistributionSection is the output of PrintFrequencyDistribution
+	DistributionSection
+	// TopTermsSection is the output of PrintValueFrequency for the 5 most
+	// frequent values
+	TopTermsSection
+)
+
+// PrintSections prints the sections of the report in the order given,
+// separated by blank lines
+func (is IntStats) PrintSections(w io.Writer, sections ...Section) {
+	for i, section := range sections {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		switch section {
+		case SummarySection:
+			is.PrintSummary(w)
+		case DistributionSection:
+			is.PrintFrequencyDistribution(w)
+			fmt.Fprintln(w)
+		case TopTermsSection:
+			is.PrintValueFrequency(w, 5)
+		}
+	}
 }
 
 // PrintValueFrequency prints out the most frequent values in most
@@ -300,8 +949,8 @@ func (is IntStats) PrintValueFrequency(w io.Writer, topValues int) {
 	if is.Count > 0 {
 		fmt.Fprintf(w, "= Top Value Frequency ==========\n")
 		for i, pair := range is.GetTermFrequency(topValues) {
-			fmt.Fprintf(w, "%2d. %8d :%8d (%4.2f%%)\n", i+1, pair.Value, pair.Frequency,
-				100.0*float64(pair.Frequency)/float64(is.Count))
+			fmt.Fprintf(w, "%2d. %8s :%8d (%s)\n", i+1, is.formatValue(pair.Value), pair.Frequency,
+				is.formatFraction(is.fraction(pair.Frequency)))
 		}
 	}
 }
@@ -310,33 +959,93 @@ func (is IntStats) PrintValueFrequency(w io.Writer, topValues int) {
 // sized bucket. Additionally, if the approximation window didn't capture all the possible values
 // the range between the min and max and the frequency distribution are provided.
 func (is IntStats) PrintFrequencyDistribution(w io.Writer) {
-	fmt.Fprintf(w, "= Distribution (size: %d number: %d) ====\n", is.BucketSize, len(is.FrequencyDistribution))
-	if is.OutlierBefore > 0 {
-		fmt.Fprintf(w, "%8d - %8d :%8d (%4.2f%%)**\n", is.Min, is.FrequencyDistributionStartingValue-1,
-			is.OutlierBefore, 100.0*float64(is.OutlierBefore)/float64(is.Count))
+	if is.Scale == SymLogScale {
+		fmt.Fprintf(w, "= Distribution (symlog number: %d) ====\n", len(is.FrequencyDistribution))
+	} else {
+		fmt.Fprintf(w, "= Distribution (size: %d number: %d) ====\n", is.BucketSize, len(is.FrequencyDistribution))
 	}
-
-	for key, value := range is.FrequencyDistribution {
-		fmt.Fprintf(w, "%8d - %8d :%8d (%4.2f%%)\n",
-			(is.FrequencyDistributionStartingValue)+(is.BucketSize*int64(key)),
-			((is.FrequencyDistributionStartingValue)+(is.BucketSize*(int64(key)+1)))-1, value,
-			100.0*float64(value)/float64(is.Count))
+	if is.PoorBucketing {
+		fmt.Fprintf(w, "!! %s of the values are outliers, the buckets don't cover the data well\n",
+			strings.TrimSpace(is.formatFraction(is.fraction(is.OutlierBefore+is.OutlierAfter))))
+	}
+	buckets := is.Buckets()
+	if is.SortBucketsByCount {
+		sort.SliceStable(buckets, func(i, j int) bool { return buckets[i].Count > buckets[j].Count })
 	}
-	if is.OutlierAfter > 0 {
-		fmt.Fprintf(w, "%8d - %8d :%8d (%4.2f%%)**\n",
-			is.FrequencyDistributionStartingValue+(is.BucketSize*int64(len(is.FrequencyDistribution)))+1,
-			is.Max, is.OutlierAfter, 100.0*float64(is.OutlierAfter)/float64(is.Count))
+	var low, high int64
+	if is.MarkIQROutliers {
+		low, high = is.OutlierBounds()
 	}
+	empty := 0
+	var cumulative int64
+	for _, b := range buckets {
+		cumulative += b.Count
+		if is.SkipEmptyBuckets && b.Count == 0 {
+			empty++
+			continue
+		}
+		printEmptyBuckets(w, empty)
+		empty = 0
+		marker := ""
+		if b.IsOutlier {
+			marker = "**"
+		}
+		if is.BucketMin != nil && !b.IsOutlier && b.Count > 0 {
+			i := is.bucketOffset(b.LowerBound)
+			marker = fmt.Sprintf(" [%s - %s]", is.formatValue(is.BucketMin[i]), is.formatValue(is.BucketMax[i]))
+		}
+		if is.MarkIQROutliers && (b.UpperBound < low || b.LowerBound > high) {
+			marker += " !!"
+		}
+		if is.ShowCumulative {
+			marker = fmt.Sprintf(" %8d (%s)%s", cumulative, is.formatFraction(is.fraction(cumulative)), marker)
+		}
+		fmt.Fprintf(w, "%8s - %8s :%8d (%s)%s\n", is.formatValue(b.LowerBound), is.formatValue(b.UpperBound),
+			b.Count, is.formatFraction(b.Fraction), marker)
+	}
+	printEmptyBuckets(w, empty)
+}
 
+func printEmptyBuckets(w io.Writer, empty int) {
+	if empty > 0 {
+		fmt.Fprintf(w, "     ... %d empty buckets ...\n", empty)
+	}
 }
 
-// PrintSummary prints the min, max, mean, count and median
+// PrintSummary prints only the min, max, count, mean, standard deviation and
+// median, without the distribution or value frequency.
 func (is IntStats) PrintSummary(w io.Writer) {
-	fmt.Fprintf(w, "= Summary ======================\n")
-	fmt.Fprintf(w, "%-8s %12d\n", "Min", is.Min)
-	fmt.Fprintf(w, "%-8s %12d\n", "Max", is.Max)
+	if is.Name != "" {
+		fmt.Fprintf(w, "= Summary [%s] ======================\n", is.Name)
+	} else {
+		fmt.Fprintf(w, "= Summary ======================\n")
+	}
+	fmt.Fprintf(w, "%-8s %12s\n", "Min", is.formatValue(is.Min))
+	fmt.Fprintf(w, "%-8s %12s\n", "Max", is.formatValue(is.Max))
 	fmt.Fprintf(w, "%-8s %12d\n", "Count", is.Count)
-	fmt.Fprintf(w, "%-8s %16.3f\n", "Mean", is.Mean)
-	fmt.Fprintf(w, "%-8s %12d\n", "Median", is.Median)
+	if is.Verbose {
+		fmt.Fprintf(w, "%-8s %12s\n", "2nd Min", is.formatValue(is.SecondMin))
+		fmt.Fprintf(w, "%-8s %12s\n", "2nd Max", is.formatValue(is.SecondMax))
+		fmt.Fprintf(w, "%-8s %12s\n", "Sum", is.Sum)
+		fmt.Fprintf(w, "%-8s %16.6g\n", "SumSq", is.SumOfSquares())
+	}
+	fmt.Fprintf(w, "%-8s %12d\n", "Negative", is.NegativeCount)
+	fmt.Fprintf(w, "%-8s %12d\n", "Zero", is.ZeroCount)
+	fmt.Fprintf(w, "%-8s %12d\n", "Positive", is.PositiveCount)
+	mean, stdDev := is.Mean, is.StdDev
+	if is.Count == 0 {
+		// They're undefined without values
+		mean, stdDev = math.NaN(), math.NaN()
+	}
+	fmt.Fprintf(w, "%-8s %s\n", "Mean", is.formatFloat(mean))
+	fmt.Fprintf(w, "%-8s %s\n", "StdDev", is.formatFloat(stdDev))
+	fmt.Fprintf(w, "%-8s %12s\n", "Median", is.formatValue(is.Median))
+	if is.SampleRate > 0 {
+		fmt.Fprintf(w, "%-8s %16.3f\n", "Est Count", is.EstimatedCount())
+	}
+	if is.Duration > 0 {
+		fmt.Fprintf(w, "%-8s %16.3f/s\n", "Rate", is.Rate())
+		fmt.Fprintf(w, "%-8s %s/s\n", "Sum Rate", is.formatFloat(is.SumRate()))
+	}
 
 }
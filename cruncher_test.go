@@ -1,9 +1,14 @@
 package cruncher
 
 import (
+	"bytes"
+	"encoding/binary"
+	"io"
 	"math"
+	"math/big"
 	"math/rand"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -147,3 +152,611 @@ func gausian(mean int, standardDeviation float64) int64 {
 	return int64(mean) + int64(y1*standardDeviation)
 
 }
+
+func TestIdenticalValues(t *testing.T) {
+	a := NewAccumulator(1000, 10)
+	for i := 0; i < 100; i++ {
+		a.Add(42)
+	}
+	intStats := a.GetStats()
+	if actual, correct := len(intStats.FrequencyDistribution), 1; actual != correct {
+		t.Errorf("Buckets: %d != %d", actual, correct)
+	}
+	if actual, correct := intStats.BucketSize, int64(1); actual != correct {
+		t.Errorf("BucketSize: %d != %d", actual, correct)
+	}
+	if actual, correct := intStats.FrequencyDistribution[0], int64(100); actual != correct {
+		t.Errorf("Bucket count: %d != %d", actual, correct)
+	}
+	if intStats.OutlierBefore != 0 || intStats.OutlierAfter != 0 {
+		t.Errorf("Unexpected outliers %d, %d", intStats.OutlierBefore, intStats.OutlierAfter)
+	}
+}
+
+func TestTinyRange(t *testing.T) {
+	a := NewAccumulator(1000, 10)
+	for i := 0; i < 30; i++ {
+		a.Add(int64(i%3 + 1))
+	}
+	intStats := a.GetStats()
+	if actual, correct := len(intStats.FrequencyDistribution), 3; actual != correct {
+		t.Errorf("Buckets: %d != %d", actual, correct)
+	}
+	for i, v := range intStats.FrequencyDistribution {
+		if v != 10 {
+			t.Errorf("Bucket %d should contain 10 values but had %d", i, v)
+		}
+	}
+	var buf bytes.Buffer
+	intStats.PrintFrequencyDistribution(&buf)
+	if strings.Contains(buf.String(), "Inf") || strings.Contains(buf.String(), "NaN") {
+		t.Errorf("Distribution contains invalid values:\n%s", buf.String())
+	}
+}
+
+func TestName(t *testing.T) {
+	a := NewAccumulator(1000, 5)
+	a.Add(1)
+	a.Add(2)
+	var buf bytes.Buffer
+	a.Print(&buf)
+	if !strings.HasPrefix(buf.String(), "= Summary ======================\n") {
+		t.Errorf("Unnamed header changed:\n%s", buf.String())
+	}
+
+	a.Name = "latency_ms"
+	buf.Reset()
+	a.Print(&buf)
+	if !strings.HasPrefix(buf.String(), "= Summary [latency_ms] ====") {
+		t.Errorf("Name missing from header:\n%s", buf.String())
+	}
+	if actual, correct := a.GetStats().Name, "latency_ms"; actual != correct {
+		t.Errorf("Name: %s != %s", actual, correct)
+	}
+}
+
+func TestSkipEmptyBuckets(t *testing.T) {
+	a := NewAccumulator(1000, 100)
+	for i := 0; i < 10; i++ {
+		a.Add(0)
+		a.Add(999)
+	}
+	var buf bytes.Buffer
+	a.GetStats().PrintFrequencyDistribution(&buf)
+	if actual, correct := strings.Count(buf.String(), "\n"), 101; actual != correct {
+		t.Errorf("Default distribution lines: %d != %d", actual, correct)
+	}
+
+	a.SkipEmptyBuckets = true
+	buf.Reset()
+	a.GetStats().PrintFrequencyDistribution(&buf)
+	if actual, correct := strings.Count(buf.String(), "\n"), 4; actual != correct {
+		t.Errorf("Sparse distribution lines: %d != %d\n%s", actual, correct, buf.String())
+	}
+	if !strings.Contains(buf.String(), "98 empty buckets") {
+		t.Errorf("Empty buckets weren't collapsed:\n%s", buf.String())
+	}
+}
+
+func TestSum(t *testing.T) {
+	a := NewAccumulator(1000, 5)
+	for _, v := range []int64{5, -3, 12, 0, 7} {
+		a.Add(v)
+	}
+	if actual, correct := a.GetStats().Sum.Int64(), int64(21); actual != correct {
+		t.Errorf("Sum: %d != %d", actual, correct)
+	}
+
+	a = NewAccumulator(1000, 5)
+	a.Add(math.MaxInt64)
+	a.Add(math.MaxInt64)
+	a.Add(math.MinInt64)
+	a.Add(-5)
+	correct := new(big.Int).SetInt64(math.MaxInt64)
+	correct.Sub(correct, big.NewInt(6))
+	intStats := a.GetStats()
+	if intStats.Sum.Cmp(correct) != 0 {
+		t.Errorf("Sum: %s != %s", intStats.Sum, correct)
+	}
+	if actual, correct := intStats.Mean, (float64(math.MaxInt64)-6)/4; actual != correct {
+		t.Errorf("Mean: %f != %f", actual, correct)
+	}
+
+	a = NewAccumulator(1000, 5)
+	a.Add(-1)
+	a.Add(-2)
+	if actual, correct := a.GetStats().Mean, -1.5; actual != correct {
+		t.Errorf("Negative Mean: %f != %f", actual, correct)
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	a := NewAccumulator(1000, 5)
+	for _, v := range []int64{2, 4, 4, 4, 5, 5, 7, 9} {
+		a.Add(v)
+	}
+	var buf bytes.Buffer
+	a.GetStats().PrintSummary(&buf)
+	summary := buf.String()
+	for _, expected := range []string{"= Summary", "Min", "Max", "Count", "Mean", "StdDev              2.000", "Median"} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("Summary is missing %q:\n%s", expected, summary)
+		}
+	}
+	for _, unexpected := range []string{"Distribution", "Top Value Frequency"} {
+		if strings.Contains(summary, unexpected) {
+			t.Errorf("Summary shouldn't contain %q:\n%s", unexpected, summary)
+		}
+	}
+}
+
+func TestMedianOrdering(t *testing.T) {
+	values := []int64{5, 3, 9, 1, 7}
+	if actual, correct := medianOf(values), int64(5); actual != correct {
+		t.Errorf("Median: %d != %d", actual, correct)
+	}
+	if actual, correct := values, []int64{5, 3, 9, 1, 7}; !equalInt64s(actual, correct) {
+		t.Errorf("medianOf reordered the values: %v != %v", actual, correct)
+	}
+	// computeMedian consumes the ordering of the values
+	if min, max, median := computeMedian(values); min != 1 || max != 9 || median != 5 {
+		t.Errorf("computeMedian: %d %d %d", min, max, median)
+	}
+	if actual, correct := values, []int64{1, 3, 5, 7, 9}; !equalInt64s(actual, correct) {
+		t.Errorf("computeMedian should sort in place: %v != %v", actual, correct)
+	}
+
+	// Summarizing doesn't reorder the values retained by the accumulator
+	a := NewAccumulator(1000, 5)
+	for _, v := range []int64{5, 3, 9, 1, 7} {
+		a.Add(v)
+	}
+	a.Summarize()
+	if actual, correct := a.remedians[0], []int64{5, 3, 9, 1, 7}; !equalInt64s(actual, correct) {
+		t.Errorf("Summarize reordered the remedian: %v != %v", actual, correct)
+	}
+}
+
+func TestTopTermsInRange(t *testing.T) {
+	is := IntStats{ValueFrequency: map[int64]int64{
+		-5: 50, 1: 10, 2: 40, 3: 30, 4: 20, 5: 60, 9: 100, 10: 5,
+	}}
+	terms := is.TopTermsInRange(1, 5, 3)
+	correct := PairList{{5, 60}, {2, 40}, {3, 30}}
+	if len(terms) != len(correct) {
+		t.Fatalf("Terms: %v != %v", terms, correct)
+	}
+	for i := range correct {
+		if terms[i] != correct[i] {
+			t.Errorf("Term %d: %v != %v", i, terms[i], correct[i])
+		}
+	}
+	if actual, correct := len(is.TopTermsInRange(6, 8, 3)), 0; actual != correct {
+		t.Errorf("Empty range: %d != %d", actual, correct)
+	}
+	if actual, correct := len(is.TopTermsInRange(-10, 10, 100)), len(is.ValueFrequency); actual != correct {
+		t.Errorf("Whole range: %d != %d", actual, correct)
+	}
+}
+
+func TestSignCounts(t *testing.T) {
+	a := NewAccumulator(10, 5)
+	for _, v := range []int64{-5, -1, 0, 0, 0, 1, 2, 3, 4, math.MinInt64, math.MaxInt64, 0} {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	if actual, correct := is.NegativeCount, int64(3); actual != correct {
+		t.Errorf("NegativeCount: %d != %d", actual, correct)
+	}
+	if actual, correct := is.ZeroCount, int64(4); actual != correct {
+		t.Errorf("ZeroCount: %d != %d", actual, correct)
+	}
+	if actual, correct := is.PositiveCount, int64(5); actual != correct {
+		t.Errorf("PositiveCount: %d != %d", actual, correct)
+	}
+	if actual, correct := is.NegativeCount+is.ZeroCount+is.PositiveCount, is.Count; actual != correct {
+		t.Errorf("Sign counts: %d != %d", actual, correct)
+	}
+	var buf bytes.Buffer
+	is.PrintSummary(&buf)
+	if report := buf.String(); !strings.Contains(report, "Negative            3") {
+		t.Errorf("Summary is missing the sign counts:\n%s", report)
+	}
+}
+
+func TestDistributionTotals(t *testing.T) {
+	for _, window := range []int{1, 2, 7, 10, 100} {
+		a := NewAccumulator(window, 5)
+		for i := int64(0); i < 1000; i++ {
+			a.Add(i % 37)
+			if err := a.Validate(); err != nil {
+				t.Fatalf("Window %d after %d values: %v", window, i+1, err)
+			}
+		}
+	}
+}
+
+func TestMergeDistributionTotals(t *testing.T) {
+	a, b := NewAccumulator(10, 5), NewAccumulator(10, 5)
+	for i := int64(0); i < 6; i++ {
+		a.Add(i)
+		b.Add(10 * i)
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Validate(); err != nil {
+		t.Error(err)
+	}
+	is := a.GetStats()
+	var total int64
+	for _, b := range is.Buckets() {
+		total += b.Count
+	}
+	if actual, correct := total, int64(12); actual != correct {
+		t.Errorf("Total: %d != %d", actual, correct)
+	}
+}
+
+func TestSortBucketsByCount(t *testing.T) {
+	a := NewAccumulator(1000, 5)
+	for i := int64(0); i < 100; i++ {
+		a.Add(i)
+		if i >= 60 && i < 80 {
+			a.Add(i)
+		}
+	}
+	a.SortBucketsByCount = true
+	var buf bytes.Buffer
+	a.GetStats().PrintFrequencyDistribution(&buf)
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[1], "      60 -       79 :      40") {
+		t.Errorf("First bucket should be the fullest:\n%s", buf.String())
+	}
+}
+
+func TestPoorBucketing(t *testing.T) {
+	a := NewAccumulator(10, 5)
+	a.OutlierWarnFraction = 0.1
+	for i := int64(0); i < 100; i++ {
+		a.Add(i)
+	}
+	is := a.GetStats()
+	if !is.PoorBucketing {
+		t.Errorf("%d + %d outliers of %d should be poor bucketing", is.OutlierBefore, is.OutlierAfter, is.Count)
+	}
+	var buf bytes.Buffer
+	is.PrintFrequencyDistribution(&buf)
+	if report := buf.String(); !strings.Contains(report, "!! 90.00% of the values are outliers") {
+		t.Errorf("Report is missing the warning:\n%s", report)
+	}
+
+	b := NewAccumulator(1000, 5)
+	b.OutlierWarnFraction = 0.1
+	for i := int64(0); i < 100; i++ {
+		b.Add(i)
+	}
+	if b.GetStats().PoorBucketing {
+		t.Errorf("Data without outliers isn't poor bucketing")
+	}
+}
+
+// permutations calls f with every ordering of values
+func permutations(values []int64, k int, f func([]int64)) {
+	if k == len(values) {
+		f(values)
+		return
+	}
+	for i := k; i < len(values); i++ {
+		values[k], values[i] = values[i], values[k]
+		permutations(values, k+1, f)
+		values[k], values[i] = values[i], values[k]
+	}
+}
+
+func TestExactSmallMedian(t *testing.T) {
+	var is IntStats
+	permutations([]int64{-7, 3, 10, 42, 100}, 0, func(values []int64) {
+		order := append([]int64(nil), values...)
+		if actual, correct := is.exactSmallMedian(values, false), int64(10); actual != correct {
+			t.Errorf("Median of %v: %d != %d", values, actual, correct)
+		}
+		if !equalInt64s(values, order) {
+			t.Errorf("Values were reordered: %v != %v", values, order)
+		}
+	})
+	permutations([]int64{1, 2, 3, 4}, 0, func(values []int64) {
+		if actual, correct := is.exactSmallMedian(values, false), int64(2); actual != correct {
+			t.Errorf("Median of %v: %d != %d", values, actual, correct)
+		}
+	})
+	a := NewAccumulator(10, 5)
+	for _, v := range []int64{4, 1, 3, 2} {
+		a.Add(v)
+	}
+	if actual, correct := a.GetStats().Median, int64(2); actual != correct {
+		t.Errorf("Median: %d != %d", actual, correct)
+	}
+}
+
+func TestAllTermFrequencies(t *testing.T) {
+	is := IntStats{ValueFrequency: map[int64]int64{7: 3, -2: 5, 4: 3, 9: 1, 1: 3}}
+	terms := is.AllTermFrequencies()
+	correct := PairList{{-2, 5}, {1, 3}, {4, 3}, {7, 3}, {9, 1}}
+	if actual, correct := len(terms), len(is.ValueFrequency); actual != correct {
+		t.Fatalf("Terms: %d != %d", actual, correct)
+	}
+	for i := range correct {
+		if terms[i] != correct[i] {
+			t.Errorf("Term %d: %v != %v", i, terms[i], correct[i])
+		}
+	}
+}
+
+func TestVerboseSummary(t *testing.T) {
+	a := NewAccumulator(100, 5)
+	for i := int64(1); i <= 10; i++ {
+		a.Add(i)
+	}
+	var buf bytes.Buffer
+	a.GetStats().PrintSummary(&buf)
+	if strings.Contains(buf.String(), "\nSum ") {
+		t.Errorf("Default summary shouldn't include the Sum:\n%s", buf.String())
+	}
+	a.Verbose = true
+	buf.Reset()
+	a.GetStats().PrintSummary(&buf)
+	for _, expected := range []string{"Sum                55\n", "SumSq                 385\n"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Verbose summary is missing %q:\n%s", expected, buf.String())
+		}
+	}
+}
+
+// FuzzAdd adds arbitrary int64 values, decoded 8 bytes at a time, to small
+// accumulators so the distribution is laid out and Remedians collapse
+func FuzzAdd(f *testing.F) {
+	seed := func(values ...int64) []byte {
+		data := make([]byte, 8*len(values))
+		for i, v := range values {
+			binary.LittleEndian.PutUint64(data[8*i:], uint64(v))
+		}
+		return data
+	}
+	f.Add(uint8(3), uint8(2), seed(1, 2, 3, 4, 5))
+	f.Add(uint8(2), uint8(5), seed(math.MinInt64, math.MaxInt64, 0, -1, 1))
+	f.Add(uint8(1), uint8(1), seed(math.MaxInt64, math.MaxInt64, math.MinInt64))
+	f.Add(uint8(4), uint8(3), seed(math.MinInt64, math.MinInt64+1, math.MinInt64, 7, math.MaxInt64-1, math.MaxInt64))
+	f.Fuzz(func(t *testing.T, window, buckets uint8, data []byte) {
+		a := NewAccumulator(int(window%16), int(buckets%16))
+		for len(data) >= 8 {
+			a.Add(int64(binary.LittleEndian.Uint64(data)))
+			data = data[8:]
+		}
+		if err := a.Validate(); err != nil {
+			t.Fatalf("Before Summarize: %v", err)
+		}
+		a.Summarize()
+		a.Print(io.Discard)
+		is := a.GetStats()
+		if err := a.Validate(); err != nil {
+			t.Fatalf("After Summarize: %v", err)
+		}
+		if is.Count > 0 && (is.Median < is.Min || is.Median > is.Max) {
+			t.Fatalf("Median %d is outside of %d - %d", is.Median, is.Min, is.Max)
+		}
+	})
+}
+
+func TestSecondExtremes(t *testing.T) {
+	a := NewAccumulator(100, 10)
+	a.Add(50)
+	if is := a.GetStats(); is.SecondMin != 50 || is.SecondMax != 50 {
+		t.Errorf("Single value: %d, %d != 50, 50", is.SecondMin, is.SecondMax)
+	}
+	a.Add(40)
+	if is := a.GetStats(); is.SecondMin != 50 || is.SecondMax != 40 {
+		t.Errorf("Two values: %d, %d != 50, 40", is.SecondMin, is.SecondMax)
+	}
+	for i := int64(1); i <= 100; i++ {
+		a.Add(i)
+	}
+	a.Add(1000000)
+	is := a.GetStats()
+	if actual, correct := is.Max, int64(1000000); actual != correct {
+		t.Errorf("Max: %d != %d", actual, correct)
+	}
+	if actual, correct := is.SecondMax, int64(100); actual != correct {
+		t.Errorf("SecondMax: %d != %d", actual, correct)
+	}
+	if actual, correct := is.SecondMin, int64(2); actual != correct {
+		t.Errorf("SecondMin: %d != %d", actual, correct)
+	}
+	a.Add(1)
+	if actual, correct := a.GetStats().SecondMin, int64(1); actual != correct {
+		t.Errorf("Duplicate Min: %d != %d", actual, correct)
+	}
+
+	b := NewAccumulator(100, 10)
+	b.Add(-5)
+	b.Add(2000000)
+	b.Add(7)
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if is := a.GetStats(); is.SecondMin != 1 || is.SecondMax != 1000000 {
+		t.Errorf("Merged: %d, %d != 1, 1000000", is.SecondMin, is.SecondMax)
+	}
+
+	a.Verbose = true
+	var buf bytes.Buffer
+	a.Print(&buf)
+	if !strings.Contains(buf.String(), "2nd Max       1000000\n") {
+		t.Errorf("Verbose report is missing 2nd Max:\n%s", buf.String())
+	}
+}
+
+func TestCoefficientOfVariation(t *testing.T) {
+	a := NewAccumulator(100, 10)
+	for _, v := range []int64{2, 4, 4, 4, 5, 5, 7, 9} {
+		a.Add(v)
+	}
+	if actual, correct := a.GetStats().CoefficientOfVariation(), 0.4; math.Abs(actual-correct) > 1e-9 {
+		t.Errorf("CoefficientOfVariation: %f != %f", actual, correct)
+	}
+	b := NewAccumulator(100, 10)
+	b.Add(-10)
+	b.Add(10)
+	if actual := b.GetStats().CoefficientOfVariation(); !math.IsNaN(actual) {
+		t.Errorf("CoefficientOfVariation with a mean of 0: %f isn't NaN", actual)
+	}
+}
+
+func TestGini(t *testing.T) {
+	uniform := NewAccumulator(1000, 10)
+	for v := int64(0); v < 1000; v++ {
+		uniform.Add(v)
+	}
+	if actual := uniform.GetStats().Gini(); math.Abs(actual) > 0.01 {
+		t.Errorf("Uniform Gini: %f isn't near 0", actual)
+	}
+	spike := NewAccumulator(1000, 100)
+	spike.Add(0)
+	for i := 0; i < 998; i++ {
+		spike.Add(500)
+	}
+	spike.Add(1000)
+	if actual := spike.GetStats().Gini(); actual < 0.95 {
+		t.Errorf("Spike Gini: %f isn't near 1", actual)
+	}
+}
+
+func TestMonotonic(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		values     []int64
+		increasing bool
+		decreasing bool
+	}{
+		{"sorted", []int64{1, 2, 2, 5, 9}, true, false},
+		{"reversed", []int64{9, 5, 2, 2, 1}, false, true},
+		{"random", []int64{3, 9, 1, 4, 4}, false, false},
+		{"constant", []int64{4, 4, 4}, true, true},
+		{"single", []int64{4}, true, true},
+		{"empty", nil, false, false},
+	} {
+		a := NewAccumulator(100, 10)
+		for _, v := range test.values {
+			a.Add(v)
+		}
+		is := a.GetStats()
+		if actual, correct := is.IsMonotonicIncreasing, test.increasing; actual != correct {
+			t.Errorf("%s increasing: %t != %t", test.name, actual, correct)
+		}
+		if actual, correct := is.IsMonotonicDecreasing, test.decreasing; actual != correct {
+			t.Errorf("%s decreasing: %t != %t", test.name, actual, correct)
+		}
+	}
+
+	a, b := NewAccumulator(100, 10), NewAccumulator(100, 10)
+	for i := int64(0); i < 10; i++ {
+		a.Add(i)
+		b.Add(i + 10)
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if !a.GetStats().IsMonotonicIncreasing {
+		t.Errorf("Consecutive sorted ranges should remain increasing")
+	}
+	a.Add(15)
+	if a.GetStats().IsMonotonicIncreasing {
+		t.Errorf("Adding a smaller value after the merge should clear increasing")
+	}
+}
+
+func TestEmptyContract(t *testing.T) {
+	zero := func(values ...float64) bool {
+		for _, v := range values {
+			if v != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	for _, is := range []IntStats{{}, NewAccumulator(100, 10).GetStats()} {
+		for _, test := range []struct {
+			name string
+			ok   func() bool
+		}{
+			{"AllTermFrequencies", func() bool { return len(is.AllTermFrequencies()) == 0 }},
+			{"BoxPlot", func() bool { return is.BoxPlot() == BoxPlotData{} }},
+			{"BucketCountFor", func() bool {
+				count, lower, upper := is.BucketCountFor(5)
+				return zero(float64(count), float64(lower), float64(upper))
+			}},
+			{"Buckets", func() bool { return len(is.Buckets()) == 0 }},
+			{"CoefficientOfVariation", func() bool { return math.IsNaN(is.CoefficientOfVariation()) }},
+			{"Gini", func() bool { return math.IsNaN(is.Gini()) }},
+			{"Diff", func() bool {
+				d := is.Diff(is)
+				return d.Count.Absolute == 0 && math.IsNaN(d.Count.Percent) && len(d.Buckets) == 0
+			}},
+			{"Distance", func() bool {
+				d, err := is.Distance(is, JensenShannon)
+				return d == 0 && err == nil
+			}},
+			{"DistributionFractions", func() bool { return len(is.DistributionFractions()) == 0 }},
+			{"DistributionMedian", func() bool { return is.DistributionMedian() == 0 }},
+			{"Entropy", func() bool { return is.Entropy() == 0 }},
+			{"EstimatedCount", func() bool { return zero(is.EstimatedCount(), is.EstimatedSum()) }},
+			{"GetTermFrequency", func() bool { return len(is.GetTermFrequency(5)) == 0 }},
+			{"GetWeightedTermFrequency", func() bool { return len(is.GetWeightedTermFrequency(5)) == 0 }},
+			{"IsBimodal", func() bool {
+				bimodal, peaks := is.IsBimodal()
+				return !bimodal && len(peaks) == 0
+			}},
+			{"ModalBucket", func() bool {
+				index, lower, upper, count := is.ModalBucket()
+				return index == -1 && zero(float64(lower), float64(upper), float64(count))
+			}},
+			{"Mode", func() bool {
+				value, ok := is.Mode()
+				return value == 0 && !ok
+			}},
+			{"OutlierBounds", func() bool {
+				low, high := is.OutlierBounds()
+				return zero(float64(low), float64(high))
+			}},
+			{"PeaksWithProminence", func() bool { return len(is.PeaksWithProminence(DefaultProminence)) == 0 }},
+			{"Percentile", func() bool {
+				return zero(float64(is.Percentile(0.5)), float64(is.PercentileFromDistribution(0.5)), is.PercentileRank(3))
+			}},
+			{"ProportionWithin", func() bool { return is.ProportionWithin(1) == 0 }},
+			{"Quantiles", func() bool {
+				q := is.Quantiles(0.1, 0.9)
+				return len(q) == 2 && zero(float64(q[0]), float64(q[1]))
+			}},
+			{"Range", func() bool { return is.Range() == 0 }},
+			{"Rate", func() bool { return zero(is.Rate(), is.SumRate(), is.SumOfSquares()) }},
+			{"Resample", func() bool { return is.Resample(3).Count == 0 }},
+			{"TopTermsInRange", func() bool { return len(is.TopTermsInRange(0, 10, 5)) == 0 }},
+			{"TrimmedMean", func() bool { return zero(is.TrimmedMean(0.1), is.WinsorizedMean(0.1)) }},
+			{"Print", func() bool {
+				is.Print(io.Discard)
+				return true
+			}},
+			{"Write", func() bool {
+				return is.WriteGnuplot(io.Discard) == nil && is.WriteJSON(io.Discard) == nil && is.WriteTSV(io.Discard) == nil
+			}},
+			{"MarshalBinary", func() bool {
+				_, err := is.MarshalBinary()
+				return err == nil
+			}},
+		} {
+			if !test.ok() {
+				t.Errorf("%s doesn't meet the empty contract", test.name)
+			}
+		}
+	}
+}
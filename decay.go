@@ -0,0 +1,27 @@
+package cruncher
+
+import "math"
+
+// decay maintains an exponentially weighted mean where the weight of each
+// value halves after every halfLife additional values
+type decay struct {
+	factor float64
+	sum    float64
+	count  float64
+}
+
+func newDecay(halfLife float64) *decay {
+	return &decay{factor: math.Exp2(-1 / halfLife)}
+}
+
+func (d *decay) add(value int64) {
+	d.sum = d.sum*d.factor + float64(value)
+	d.count = d.count*d.factor + 1
+}
+
+func (d *decay) mean() float64 {
+	if d.count == 0 {
+		return 0
+	}
+	return d.sum / d.count
+}
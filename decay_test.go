@@ -0,0 +1,45 @@
+package cruncher
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDecay(t *testing.T) {
+	const halfLife = 100
+	a := NewAccumulatorWithOptions(WithDecay(halfLife))
+	for i := 0; i < 10000; i++ {
+		a.Add(0)
+	}
+	if actual, correct := a.GetStats().DecayedMean, 0.0; actual != correct {
+		t.Errorf("DecayedMean: %f != %f", actual, correct)
+	}
+	for i := 0; i < halfLife; i++ {
+		a.Add(100)
+	}
+	// After one half life the old level has half of the weight
+	is := a.GetStats()
+	if actual, correct := is.DecayedMean, 50.0; math.Abs(actual-correct) > 1 {
+		t.Errorf("DecayedMean after one half life: %f != %f", actual, correct)
+	}
+	if actual, correct := is.DecayedCount, 1/(1-math.Exp2(-1.0/halfLife)); math.Abs(actual-correct) > 1 {
+		t.Errorf("DecayedCount: %f != %f", actual, correct)
+	}
+	previous := is.DecayedMean
+	for i := 0; i < 4; i++ {
+		for j := 0; j < halfLife; j++ {
+			a.Add(100)
+		}
+		if mean := a.GetStats().DecayedMean; mean <= previous {
+			t.Errorf("DecayedMean should converge toward 100: %f <= %f", mean, previous)
+		} else {
+			previous = mean
+		}
+	}
+	if previous < 96 {
+		t.Errorf("DecayedMean after five half lives should be near 100 but was %f", previous)
+	}
+	if actual, correct := is.Mean, 100.0*halfLife/(10000+halfLife); math.Abs(actual-correct) > 1e-9 {
+		t.Errorf("Mean should be unaffected: %f != %f", actual, correct)
+	}
+}
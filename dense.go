@@ -0,0 +1,59 @@
+package cruncher
+
+// denseFrequency counts every value between min and max exactly in a slice
+// indexed by value-min, avoiding the hashing and heap upkeep of spaceSaving
+// for small dense domains such as status codes or percentages
+type denseFrequency struct {
+	min    int64
+	counts []int64
+}
+
+// MaxDenseRange is the largest number of values WithDenseFrequency counts
+// in a slice, 8 bytes each, the rest of a wider range falls back to the
+// bounded map
+const MaxDenseRange = 1 << 20
+
+// newDenseFrequency counts the values from min up to max, or the first
+// MaxDenseRange of them
+func newDenseFrequency(min, max int64) *denseFrequency {
+	n := span(min, max)
+	if n >= MaxDenseRange {
+		n = MaxDenseRange - 1
+	}
+	return &denseFrequency{min: min, counts: make([]int64, n+1)}
+}
+
+// add counts value and reports whether it was within the range
+func (d *denseFrequency) add(value int64) bool {
+	return d.addCount(value, 1)
+}
+
+// addCount adds n occurrences of value and reports whether it was within
+// the range
+func (d *denseFrequency) addCount(value, n int64) bool {
+	if value < d.min {
+		return false
+	}
+	i := span(d.min, value)
+	if i >= uint64(len(d.counts)) {
+		return false
+	}
+	d.counts[i] += n
+	return true
+}
+
+// each calls fn with every value counted
+func (d *denseFrequency) each(fn func(value, count int64)) {
+	for i, c := range d.counts {
+		if c > 0 {
+			fn(d.min+int64(i), c)
+		}
+	}
+}
+
+// addTo adds the values counted to frequencies
+func (d *denseFrequency) addTo(frequencies map[int64]int64) {
+	d.each(func(value, count int64) {
+		frequencies[value] += count
+	})
+}
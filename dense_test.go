@@ -0,0 +1,100 @@
+package cruncher
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// statusCodes are mostly in 100..599 with an occasional value outside it
+func statusCodes(n int) []int64 {
+	codes := []int64{200, 200, 200, 201, 204, 301, 304, 400, 404, 404, 500, 503}
+	values := make([]int64, n)
+	for i := range values {
+		values[i] = codes[i%len(codes)]
+		if i%97 == 0 {
+			values[i] = 999
+		}
+	}
+	return values
+}
+
+func TestDenseFrequency(t *testing.T) {
+	values := statusCodes(1000)
+	sparse := NewAccumulatorWithOptions(WithWindow(100))
+	dense := NewAccumulatorWithOptions(WithWindow(100), WithDenseFrequency(100, 599))
+	for _, v := range values {
+		sparse.Add(v)
+		dense.Add(v)
+	}
+	s, d := sparse.GetStats(), dense.GetStats()
+	if !reflect.DeepEqual(s.ValueFrequency, d.ValueFrequency) {
+		t.Errorf("ValueFrequency: %v != %v", d.ValueFrequency, s.ValueFrequency)
+	}
+	if actual, correct := len(dense.frequency.counters), 1; actual != correct {
+		t.Errorf("Map terms: %d != %d", actual, correct)
+	}
+	if actual, correct := d.GetTermFrequency(1)[0].Value, int64(200); actual != correct {
+		t.Errorf("Top term: %d != %d", actual, correct)
+	}
+}
+
+func TestDenseFrequencyMerge(t *testing.T) {
+	values := statusCodes(600)
+	whole := NewAccumulatorWithOptions(WithWindow(1000))
+	a := NewAccumulatorWithOptions(WithWindow(1000), WithDenseFrequency(100, 299))
+	b := NewAccumulatorWithOptions(WithWindow(1000), WithDenseFrequency(200, 599))
+	for i, v := range values {
+		whole.Add(v)
+		if i%2 == 0 {
+			a.Add(v)
+		} else {
+			b.Add(v)
+		}
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if actual, correct := a.GetStats().ValueFrequency, whole.GetStats().ValueFrequency; !reflect.DeepEqual(actual, correct) {
+		t.Errorf("Merged: %v != %v", actual, correct)
+	}
+}
+
+func benchmarkStatusCodes(b *testing.B, opts ...Option) {
+	values := statusCodes(20000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		a := NewAccumulatorWithOptions(opts...)
+		for _, v := range values {
+			a.Add(v)
+		}
+	}
+}
+
+func BenchmarkStatusCodes(b *testing.B) {
+	benchmarkStatusCodes(b, WithWindow(20000))
+}
+
+func BenchmarkStatusCodesDense(b *testing.B) {
+	benchmarkStatusCodes(b, WithWindow(20000), WithDenseFrequency(100, 599))
+}
+
+func TestDenseFrequencyHugeRange(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithWindow(100), WithDenseFrequency(math.MinInt64, math.MaxInt64))
+	if actual, correct := len(a.dense.counts), MaxDenseRange; actual != correct {
+		t.Fatalf("Dense counts: %d != %d", actual, correct)
+	}
+	for _, v := range []int64{math.MinInt64, math.MinInt64 + MaxDenseRange, 0, 0, math.MaxInt64} {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	for v, correct := range map[int64]int64{math.MinInt64: 1, math.MinInt64 + MaxDenseRange: 1, 0: 2, math.MaxInt64: 1} {
+		if actual := is.ValueFrequency[v]; actual != correct {
+			t.Errorf("Frequency of %d: %d != %d", v, actual, correct)
+		}
+	}
+	if actual, correct := len(a.frequency.counters), 3; actual != correct {
+		t.Errorf("Map terms: %d != %d", actual, correct)
+	}
+}
@@ -0,0 +1,134 @@
+package cruncher
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Delta is the change in a statistic between two IntStats
+type Delta struct {
+	// Absolute is the new value less the original value
+	Absolute float64
+	// Percent is Absolute relative to the original value, it's NaN when the
+	// original value is zero
+	Percent float64
+}
+
+// StatsDiff describes how the stats changed between two IntStats
+type StatsDiff struct {
+	Count  Delta
+	Min    Delta
+	Max    Delta
+	Mean   Delta
+	Median Delta
+	StdDev Delta
+	// Buckets is the change in the count of each bucket, it's only set when
+	// both distributions have the same layout
+	Buckets []int64
+}
+
+func newDelta(original, updated float64) Delta {
+	d := Delta{Absolute: updated - original, Percent: math.NaN()}
+	if original != 0 {
+		d.Percent = 100 * d.Absolute / math.Abs(original)
+	}
+	return d
+}
+
+// Diff describes how the stats changed from is to other
+func (is IntStats) Diff(other IntStats) StatsDiff {
+	d := StatsDiff{
+		Count:  newDelta(float64(is.Count), float64(other.Count)),
+		Min:    newDelta(float64(is.Min), float64(other.Min)),
+		Max:    newDelta(float64(is.Max), float64(other.Max)),
+		Mean:   newDelta(is.Mean, other.Mean),
+		Median: newDelta(float64(is.Median), float64(other.Median)),
+		StdDev: newDelta(is.StdDev, other.StdDev),
+	}
+	if is.SameLayout(other) {
+		d.Buckets = make([]int64, len(is.FrequencyDistribution))
+		for i := range d.Buckets {
+			d.Buckets[i] = other.FrequencyDistribution[i] - is.FrequencyDistribution[i]
+		}
+	}
+	return d
+}
+
+// WriteDiff prints the changes in an ascii formatted human readable form
+func (d StatsDiff) WriteDiff(w io.Writer) {
+	fmt.Fprintf(w, "= Diff =========================\n")
+	for _, field := range []struct {
+		name  string
+		delta Delta
+	}{
+		{"Count", d.Count},
+		{"Min", d.Min},
+		{"Max", d.Max},
+		{"Mean", d.Mean},
+		{"Median", d.Median},
+		{"StdDev", d.StdDev},
+	} {
+		fmt.Fprintf(w, "%-8s %+16.3f (%s)\n", field.name, field.delta.Absolute, formatPercent(field.delta.Percent))
+	}
+	for i, v := range d.Buckets {
+		fmt.Fprintf(w, "Bucket %3d %+14d\n", i, v)
+	}
+}
+
+func formatPercent(p float64) string {
+	if math.IsNaN(p) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.2f%%", p)
+}
+
+// PrintComparison prints the summaries of several stats side by side, one
+// column per stats headed by its label, or its Name when labels runs out.
+// When every distribution has the same layout the bucket counts are
+// compared as well, otherwise only the summaries are.
+func PrintComparison(w io.Writer, labels []string, stats ...IntStats) {
+	fmt.Fprintf(w, "%-10s", "")
+	for i, is := range stats {
+		label := is.Name
+		if i < len(labels) {
+			label = labels[i]
+		}
+		fmt.Fprintf(w, " %16s", label)
+	}
+	fmt.Fprintln(w)
+	for _, row := range []struct {
+		name  string
+		value func(IntStats) string
+	}{
+		{"Count", func(is IntStats) string { return fmt.Sprint(is.Count) }},
+		{"Min", func(is IntStats) string { return is.formatValue(is.Min) }},
+		{"Max", func(is IntStats) string { return is.formatValue(is.Max) }},
+		{"Mean", func(is IntStats) string { return strings.TrimSpace(is.formatFloat(is.Mean)) }},
+		{"StdDev", func(is IntStats) string { return strings.TrimSpace(is.formatFloat(is.StdDev)) }},
+		{"Median", func(is IntStats) string { return is.formatValue(is.Median) }},
+	} {
+		fmt.Fprintf(w, "%-10s", row.name)
+		for _, is := range stats {
+			fmt.Fprintf(w, " %16s", row.value(is))
+		}
+		fmt.Fprintln(w)
+	}
+	if len(stats) == 0 || len(stats[0].FrequencyDistribution) == 0 {
+		return
+	}
+	for _, is := range stats[1:] {
+		if !stats[0].SameLayout(is) {
+			return
+		}
+	}
+	for i := range stats[0].FrequencyDistribution {
+		lower, _ := stats[0].bucketBounds(i)
+		fmt.Fprintf(w, "%-10s", stats[0].formatValue(lower))
+		for _, is := range stats {
+			fmt.Fprintf(w, " %16d", is.FrequencyDistribution[i])
+		}
+		fmt.Fprintln(w)
+	}
+}
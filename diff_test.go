@@ -0,0 +1,86 @@
+package cruncher
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := IntStats{Count: 100, Min: 0, Max: 50, Mean: 20, Median: 10, StdDev: 5,
+		BucketSize: 10, FrequencyDistribution: []int64{50, 30, 20}}
+	after := IntStats{Count: 150, Min: 5, Max: 25, Mean: 25, Median: 15, StdDev: 4,
+		BucketSize: 10, FrequencyDistribution: []int64{60, 30, 60}}
+	d := before.Diff(after)
+	if actual, correct := d.Count, (Delta{50, 50}); actual != correct {
+		t.Errorf("Count: %v != %v", actual, correct)
+	}
+	if actual, correct := d.Max, (Delta{-25, -50}); actual != correct {
+		t.Errorf("Max: %v != %v", actual, correct)
+	}
+	if actual, correct := d.Mean, (Delta{5, 25}); actual != correct {
+		t.Errorf("Mean: %v != %v", actual, correct)
+	}
+	if actual, correct := d.Median, (Delta{5, 50}); actual != correct {
+		t.Errorf("Median: %v != %v", actual, correct)
+	}
+	if actual, correct := d.StdDev, (Delta{-1, -20}); actual != correct {
+		t.Errorf("StdDev: %v != %v", actual, correct)
+	}
+	if d.Min.Absolute != 5 || !math.IsNaN(d.Min.Percent) {
+		t.Errorf("Min: %v", d.Min)
+	}
+	if actual, correct := d.Buckets, []int64{10, 0, 40}; !equalInt64s(actual, correct) {
+		t.Errorf("Buckets: %v != %v", actual, correct)
+	}
+
+	var buf bytes.Buffer
+	d.WriteDiff(&buf)
+	for _, expected := range []string{"Count", "+50.000 (+50.00%)", "Min", "(n/a)", "Bucket   2"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Diff is missing %q:\n%s", expected, buf.String())
+		}
+	}
+
+	after.BucketSize = 5
+	if d := before.Diff(after); d.Buckets != nil {
+		t.Errorf("Buckets with different layouts should be nil: %v", d.Buckets)
+	}
+}
+
+func TestStdDev(t *testing.T) {
+	a := NewAccumulator(1000, 5)
+	for _, v := range []int64{2, 4, 4, 4, 5, 5, 7, 9} {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	if actual, correct := is.Variance, 4.0; math.Abs(actual-correct) > 1e-9 {
+		t.Errorf("Variance: %f != %f", actual, correct)
+	}
+	if actual, correct := is.StdDev, 2.0; math.Abs(actual-correct) > 1e-9 {
+		t.Errorf("StdDev: %f != %f", actual, correct)
+	}
+}
+
+func TestPrintComparison(t *testing.T) {
+	before := IntStats{Name: "first", Count: 10, Min: 1, Max: 9, Mean: 5, Median: 5,
+		BucketSize: 5, FrequencyDistribution: []int64{4, 6}}
+	after := IntStats{Count: 20, Min: 2, Max: 8, Mean: 4.5, Median: 4,
+		BucketSize: 5, FrequencyDistribution: []int64{12, 8}}
+	var buf bytes.Buffer
+	PrintComparison(&buf, []string{"before", "after"}, before, after)
+	report := buf.String()
+	for _, expected := range []string{"before", "after", "Count                    10               20\n",
+		"Mean                  5.000            4.500\n", "0                         4               12\n"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Comparison is missing %q:\n%s", expected, report)
+		}
+	}
+	after.BucketSize = 4
+	buf.Reset()
+	PrintComparison(&buf, nil, before, after)
+	if report := buf.String(); !strings.Contains(report, "first") || strings.Contains(report, "\n0 ") {
+		t.Errorf("Differing layouts should only compare summaries:\n%s", report)
+	}
+}
@@ -0,0 +1,49 @@
+package cruncher
+
+import "sync"
+
+// DualAccumulator maintains the stats of every value added alongside those
+// of the values added since the last SnapshotInterval, for reporting both
+// "since start" and "since last scrape" views. It's safe for concurrent use.
+type DualAccumulator struct {
+	mu       sync.Mutex
+	opts     []Option
+	lifetime *Accumulator
+	interval *Accumulator
+}
+
+// NewDualAccumulator allocates a DualAccumulator whose lifetime and
+// interval Accumulators are configured with opts
+func NewDualAccumulator(opts ...Option) *DualAccumulator {
+	return &DualAccumulator{
+		opts:     opts,
+		lifetime: NewAccumulatorWithOptions(opts...),
+		interval: NewAccumulatorWithOptions(opts...),
+	}
+}
+
+// Add adds a value to both the lifetime and the interval stats
+func (d *DualAccumulator) Add(value int64) {
+	d.mu.Lock()
+	d.lifetime.Add(value)
+	d.interval.Add(value)
+	d.mu.Unlock()
+}
+
+// Lifetime returns the stats of every value added
+func (d *DualAccumulator) Lifetime() IntStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.lifetime.Snapshot()
+}
+
+// SnapshotInterval returns the stats of the values added since the previous
+// call and starts a new interval, the lifetime stats are unaffected. No
+// value added concurrently is lost between the intervals.
+func (d *DualAccumulator) SnapshotInterval() IntStats {
+	d.mu.Lock()
+	interval := d.interval
+	d.interval = NewAccumulatorWithOptions(d.opts...)
+	d.mu.Unlock()
+	return interval.GetStats()
+}
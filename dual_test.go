@@ -0,0 +1,34 @@
+package cruncher
+
+import "testing"
+
+func TestDualAccumulator(t *testing.T) {
+	d := NewDualAccumulator(WithWindow(100))
+	for v := int64(1); v <= 10; v++ {
+		d.Add(v)
+	}
+	first := d.SnapshotInterval()
+	if actual, correct := first.Count, int64(10); actual != correct {
+		t.Errorf("First interval Count: %d != %d", actual, correct)
+	}
+	for v := int64(100); v < 105; v++ {
+		d.Add(v)
+	}
+	second := d.SnapshotInterval()
+	if actual, correct := second.Count, int64(5); actual != correct {
+		t.Errorf("Second interval Count: %d != %d", actual, correct)
+	}
+	if actual, correct := second.Min, int64(100); actual != correct {
+		t.Errorf("Second interval Min: %d != %d", actual, correct)
+	}
+	lifetime := d.Lifetime()
+	if actual, correct := lifetime.Count, int64(15); actual != correct {
+		t.Errorf("Lifetime Count: %d != %d", actual, correct)
+	}
+	if actual, correct := lifetime.Min, int64(1); actual != correct {
+		t.Errorf("Lifetime Min: %d != %d", actual, correct)
+	}
+	if actual, correct := d.SnapshotInterval().Count, int64(0); actual != correct {
+		t.Errorf("Empty interval Count: %d != %d", actual, correct)
+	}
+}
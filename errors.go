@@ -0,0 +1,28 @@
+package cruncher
+
+import "errors"
+
+// Errors returned by the package are wrapped around these sentinels so the
+// kind of failure can be checked with errors.Is
+var (
+	// ErrIncompatibleLayout is returned when frequency distributions with
+	// different bucket layouts are combined or compared, see SameLayout
+	ErrIncompatibleLayout = errors.New("cruncher: distributions have different layouts")
+	// ErrEmpty is returned when there's no data to work with
+	ErrEmpty = errors.New("cruncher: no data")
+	// ErrParse is returned when a value or encoded stats can't be parsed
+	ErrParse = errors.New("cruncher: can't parse")
+	// ErrInvalid is returned for arguments outside of what a function
+	// accepts, such as a malformed histogram, and by Validate when an
+	// invariant doesn't hold
+	ErrInvalid = errors.New("cruncher: invalid")
+	// ErrNonFinite is returned by FloatAccumulator for NaN and ±Inf with the
+	// ErrorNonFinite policy
+	ErrNonFinite = errors.New("cruncher: value isn't finite")
+	// ErrNotExact is returned when an Accumulator in exact mode is merged
+	// with one whose values were collapsed into Remedian medians
+	ErrNotExact = errors.New("cruncher: values aren't exact")
+	// ErrOutOfDomain is returned by AddChecked for values outside the domain
+	// set WithDomain
+	ErrOutOfDomain = errors.New("cruncher: value is outside the domain")
+)
@@ -0,0 +1,51 @@
+package cruncher
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithWindow(10), WithBuckets(10), WithDomain(0, 100))
+	b := NewAccumulatorWithOptions(WithWindow(10), WithBuckets(3))
+	for i := int64(0); i < 20; i++ {
+		a.Add(i)
+		b.Add(i * 7)
+	}
+	data, err := a.GetStats().MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var is IntStats
+	_, importErr := ImportHistogram([]int64{0, 10, 15}, []int64{1, 2})
+	_, widthErr := BucketsForWidth(0, 10, 0)
+	_, distanceErr := a.GetStats().Distance(a.GetStats(), DistanceMetric(-1))
+	corrupt := NewAccumulatorWithOptions()
+	corrupt.Add(1)
+	corrupt.intStats.Min = 2
+	for _, test := range []struct {
+		name     string
+		err      error
+		sentinel error
+	}{
+		{"Merge", a.Merge(b), ErrIncompatibleLayout},
+		{"AddString", a.AddString("1.5"), ErrParse},
+		{"AddChecked", a.AddChecked(101), ErrOutOfDomain},
+		{"UnmarshalBinary empty", is.UnmarshalBinary(nil), ErrEmpty},
+		{"UnmarshalBinary version", is.UnmarshalBinary([]byte{binaryVersion + 1}), ErrParse},
+		{"UnmarshalBinary truncated", is.UnmarshalBinary(data[:len(data)/2]), ErrParse},
+		{"ImportHistogram", importErr, ErrInvalid},
+		{"BucketsForWidth", widthErr, ErrInvalid},
+		{"Distance", distanceErr, ErrInvalid},
+		{"Validate", corrupt.Validate(), ErrInvalid},
+	} {
+		if !errors.Is(test.err, test.sentinel) {
+			t.Errorf("%s: %v isn't %v", test.name, test.err, test.sentinel)
+		}
+	}
+	symLog := a.GetStats()
+	symLog.Scale = SymLogScale
+	if _, err := MergeStats(a.GetStats(), symLog); !errors.Is(err, ErrIncompatibleLayout) {
+		t.Errorf("MergeStats: %v isn't %v", err, ErrIncompatibleLayout)
+	}
+}
@@ -0,0 +1,113 @@
+package cruncher
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestExactMode(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithExactMode(), WithWindow(100))
+	values := make([]int64, 0, 10001)
+	for i := 0; i < cap(values); i++ {
+		v := rand.Int63n(1000) * rand.Int63n(1000)
+		values = append(values, v)
+		a.Add(v)
+	}
+	sort.Sort(int64arr(values))
+	is := a.GetStats()
+	if actual, correct := is.Median, values[len(values)/2]; actual != correct {
+		t.Errorf("Median: %d != %d", actual, correct)
+	}
+	for _, p := range []float64{0.1, 0.5, 0.9, 0.99} {
+		if actual, correct := is.Percentile(p), values[int(p*float64(len(values)))]; actual != correct {
+			t.Errorf("Percentile %f: %d != %d", p, actual, correct)
+		}
+	}
+	if len(a.remedians) != 0 {
+		t.Errorf("Remedian shouldn't be used in exact mode")
+	}
+}
+
+func TestExactModeSmall(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithExactMode(), WithBuckets(5))
+	for _, v := range []int64{9, 1, 7, 3, 5} {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	if actual, correct := is.Median, int64(5); actual != correct {
+		t.Errorf("Median: %d != %d", actual, correct)
+	}
+	if actual, correct := is.FrequencyDistribution, []int64{1, 1, 1, 1, 1}; !equalInt64s(actual, correct) {
+		t.Errorf("Distribution: %v != %v", actual, correct)
+	}
+}
+
+func TestSummarizeContext(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithExactMode())
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 3*sortChunk+17; i++ {
+		a.Add(r.Int63n(1000000) - 500000)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := a.SummarizeContext(ctx); err != context.Canceled {
+		t.Errorf("SummarizeContext: %v != %v", err, context.Canceled)
+	}
+	if a.intStats.Sample != nil {
+		t.Errorf("Cancelled summary shouldn't be completed")
+	}
+	if err := a.SummarizeContext(context.Background()); err != nil {
+		t.Fatalf("SummarizeContext: %v", err)
+	}
+	if !sort.SliceIsSorted(a.intStats.Sample, func(i, j int) bool { return a.intStats.Sample[i] < a.intStats.Sample[j] }) {
+		t.Errorf("Sample isn't sorted")
+	}
+	if actual, correct := len(a.intStats.Sample), 3*sortChunk+17; actual != correct {
+		t.Errorf("Sample length: %d != %d", actual, correct)
+	}
+}
+
+func TestExactModePercentileMatchesMedian(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithExactMode())
+	for _, v := range []int64{4, 1, 3, 2} {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	if actual, correct := is.Percentile(0.5), is.Median; actual != correct {
+		t.Errorf("p50: %d != %d", actual, correct)
+	}
+	if actual, ok := a.Percentile(0.5); !ok || actual != is.Median {
+		t.Errorf("Polled p50: %d, %t != %d", actual, ok, is.Median)
+	}
+	if actual, ok := is.ValueAtRank(3); !ok || actual != 3 {
+		t.Errorf("ValueAtRank(3): %d, %t != 3", actual, ok)
+	}
+}
+
+func TestExactModeMerge(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithExactMode(), WithWindow(100))
+	few := NewAccumulatorWithOptions(WithWindow(100))
+	many := NewAccumulatorWithOptions(WithWindow(100))
+	for v := int64(0); v < 1000; v++ {
+		a.Add(v)
+		many.Add(v)
+		if v < 50 {
+			few.Add(v)
+		}
+	}
+	if err := a.Merge(few); err != nil {
+		t.Fatalf("Merging uncollapsed values: %v", err)
+	}
+	if actual, correct := int64(len(a.values)), a.intStats.Count; actual != correct {
+		t.Errorf("Values: %d != %d", actual, correct)
+	}
+	if err := a.Merge(many); !errors.Is(err, ErrNotExact) {
+		t.Errorf("Merging collapsed values: %v isn't %v", err, ErrNotExact)
+	}
+	if actual, correct := a.GetStats().Count, int64(1050); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+}
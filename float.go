@@ -0,0 +1,72 @@
+package cruncher
+
+import (
+	"fmt"
+	"math"
+)
+
+// NonFinitePolicy selects how a FloatAccumulator handles NaN and ±Inf
+type NonFinitePolicy int
+
+const (
+	// SkipNonFinite ignores NaN and ±Inf, they're only counted in NonFinite
+	SkipNonFinite NonFinitePolicy = iota
+	// ErrorNonFinite rejects NaN and ±Inf with an error wrapping
+	// ErrNonFinite, they're also counted in NonFinite
+	ErrorNonFinite
+	// ExtremeInf adds +Inf and -Inf as the largest and smallest int64 so they
+	// appear as extremes in Min, Max and the outliers. NaN is skipped. Both
+	// are counted in NonFinite.
+	ExtremeInf
+)
+
+// WithNonFinitePolicy selects how a FloatAccumulator handles NaN and ±Inf,
+// it defaults to SkipNonFinite
+func WithNonFinitePolicy(policy NonFinitePolicy) Option {
+	return func(a *Accumulator) {
+		a.nonFinitePolicy = policy
+	}
+}
+
+// FloatAccumulator collects statistics on float64 values by adding them to
+// an Accumulator as integer multiples of a resolution, so the stats are in
+// units of the resolution. For example with a resolution of 0.001 a value
+// of 1.5 is added as 1500. Finite values beyond the range of int64 are
+// clamped. NaN and ±Inf are handled according to WithNonFinitePolicy so
+// they don't poison Min, Max and Mean.
+type FloatAccumulator struct {
+	a          *Accumulator
+	resolution float64
+}
+
+// NewFloatAccumulator allocates a FloatAccumulator adding values as
+// multiples of resolution to an Accumulator configured by opts. A resolution
+// that isn't positive is treated as 1.
+func NewFloatAccumulator(resolution float64, opts ...Option) *FloatAccumulator {
+	if !(resolution > 0) {
+		resolution = 1
+	}
+	return &FloatAccumulator{a: NewAccumulatorWithOptions(opts...), resolution: resolution}
+}
+
+// Add adds value. An error is only returned for NaN and ±Inf under the
+// ErrorNonFinite policy.
+func (f *FloatAccumulator) Add(value float64) error {
+	if !math.IsNaN(value) && !math.IsInf(value, 0) {
+		f.a.Add(clampInt64(value / f.resolution))
+		return nil
+	}
+	f.a.intStats.NonFinite++
+	switch {
+	case f.a.nonFinitePolicy == ErrorNonFinite:
+		return fmt.Errorf("%w: %v", ErrNonFinite, value)
+	case f.a.nonFinitePolicy == ExtremeInf && !math.IsNaN(value):
+		f.a.Add(clampInt64(value))
+	}
+	return nil
+}
+
+// GetStats summarizes the values added, they're in units of the resolution
+func (f *FloatAccumulator) GetStats() IntStats {
+	return f.a.GetStats()
+}
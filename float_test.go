@@ -0,0 +1,48 @@
+package cruncher
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFloatAccumulator(t *testing.T) {
+	values := []float64{1.5, math.NaN(), -2.25, math.Inf(1), 3, math.Inf(-1)}
+	for _, test := range []struct {
+		policy   NonFinitePolicy
+		errors   int
+		count    int64
+		min, max int64
+	}{
+		{SkipNonFinite, 0, 3, -2250, 3000},
+		{ErrorNonFinite, 3, 3, -2250, 3000},
+		{ExtremeInf, 0, 5, math.MinInt64, math.MaxInt64},
+	} {
+		f := NewFloatAccumulator(0.001, WithNonFinitePolicy(test.policy))
+		errs := 0
+		for _, v := range values {
+			if err := f.Add(v); err != nil {
+				if !errors.Is(err, ErrNonFinite) {
+					t.Errorf("Policy %d: %v isn't %v", test.policy, err, ErrNonFinite)
+				}
+				errs++
+			}
+		}
+		is := f.GetStats()
+		if actual, correct := errs, test.errors; actual != correct {
+			t.Errorf("Policy %d errors: %d != %d", test.policy, actual, correct)
+		}
+		if actual, correct := is.NonFinite, int64(3); actual != correct {
+			t.Errorf("Policy %d NonFinite: %d != %d", test.policy, actual, correct)
+		}
+		if actual, correct := is.Count, test.count; actual != correct {
+			t.Errorf("Policy %d Count: %d != %d", test.policy, actual, correct)
+		}
+		if is.Min != test.min || is.Max != test.max {
+			t.Errorf("Policy %d range: %d - %d != %d - %d", test.policy, is.Min, is.Max, test.min, test.max)
+		}
+		if math.IsNaN(is.Mean) || math.IsInf(is.Mean, 0) {
+			t.Errorf("Policy %d Mean isn't finite: %f", test.policy, is.Mean)
+		}
+	}
+}
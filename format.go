@@ -0,0 +1,96 @@
+package cruncher
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Format selects how Print renders values
+type Format int
+
+const (
+	// FormatInt prints values as integers
+	FormatInt Format = iota
+	// FormatDuration prints values as nanosecond durations, e.g. 1.5ms
+	FormatDuration
+	// FormatBytes prints values as byte counts with binary units, e.g. 1.5 MiB
+	FormatBytes
+)
+
+// byteUnits are the IEC binary prefixes, each 1024 times the previous
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// formatValue renders a value, such as Min, Max or a bucket bound, using
+// the configured Format
+func (is IntStats) formatValue(value int64) string {
+	var s string
+	switch is.Format {
+	case FormatDuration:
+		s = time.Duration(value).String()
+	case FormatBytes:
+		s = formatBytes(value)
+	default:
+		s = strconv.FormatInt(value, 10)
+	}
+	return is.withUnit(s)
+}
+
+// withUnit appends the Unit, if there is one, to a formatted value
+func (is IntStats) withUnit(s string) string {
+	if is.Unit == "" {
+		return s
+	}
+	return s + " " + is.Unit
+}
+
+// formatFloat renders a computed value such as the mean, integer formatted
+// values retain 3 decimal places. Undefined values, such as the mean of no
+// values, are rendered as n/a.
+func (is IntStats) formatFloat(value float64) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		if is.Format == FormatInt {
+			return fmt.Sprintf("%16s", "n/a")
+		}
+		return fmt.Sprintf("%12s", "n/a")
+	}
+	if is.Format == FormatInt {
+		return fmt.Sprintf("%16s", is.withUnit(strconv.FormatFloat(value, 'f', 3, 64)))
+	}
+	return fmt.Sprintf("%12s", is.formatValue(int64(math.Round(value))))
+}
+
+// formatFraction renders fraction as a percentage with PercentDecimals
+// decimal places, the width grows with the precision to keep columns aligned
+func (is IntStats) formatFraction(fraction float64) string {
+	decimals := is.PercentDecimals
+	if decimals <= 0 {
+		decimals = 2
+	}
+	if math.IsNaN(fraction) || math.IsInf(fraction, 0) {
+		return fmt.Sprintf("%*s", decimals+3, "n/a")
+	}
+	return fmt.Sprintf("%*.*f%%", decimals+2, decimals, 100*fraction)
+}
+
+// formatBytes renders a byte count using the largest binary unit that keeps
+// the magnitude at least 1, negative counts are prefixed with a minus sign.
+func formatBytes(value int64) string {
+	sign := ""
+	magnitude := uint64(value)
+	if value < 0 {
+		sign = "-"
+		magnitude = -magnitude
+	}
+	if magnitude < 1024 {
+		return fmt.Sprintf("%s%d B", sign, magnitude)
+	}
+	unit := 0
+	scaled := float64(magnitude)
+	for scaled >= 1024 && unit < len(byteUnits)-1 {
+		scaled /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%s%.1f %s", sign, scaled, byteUnits[unit])
+}
@@ -0,0 +1,181 @@
+package cruncher
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	a := NewAccumulator(1000, 5)
+	for i := 1; i <= 100; i++ {
+		a.Add(int64(time.Duration(i) * 50 * time.Microsecond))
+	}
+	a.Format = FormatDuration
+	var buf bytes.Buffer
+	a.Print(&buf)
+	report := buf.String()
+	for _, expected := range []string{"Min              50µs", "Max               5ms", "ms -", "µs -"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Report is missing %q:\n%s", expected, report)
+		}
+	}
+}
+
+func TestFormatIntDefault(t *testing.T) {
+	a := NewAccumulator(1000, 5)
+	a.Add(1500)
+	var buf bytes.Buffer
+	a.Print(&buf)
+	if !strings.Contains(buf.String(), "Min              1500\n") {
+		t.Errorf("Default format changed:\n%s", buf.String())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for _, test := range []struct {
+		value    int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1572864, "1.5 MiB"},
+		{-1572864, "-1.5 MiB"},
+		{5 << 30, "5.0 GiB"},
+		{math.MaxInt64, "8.0 EiB"},
+		{math.MinInt64, "-8.0 EiB"},
+	} {
+		if actual := formatBytes(test.value); actual != test.expected {
+			t.Errorf("formatBytes(%d): %s != %s", test.value, actual, test.expected)
+		}
+	}
+
+	a := NewAccumulator(1000, 5)
+	a.Add(512)
+	a.Add(1572864)
+	a.Add(3 << 30)
+	a.Format = FormatBytes
+	var buf bytes.Buffer
+	a.Print(&buf)
+	for _, expected := range []string{"512 B", "1.5 MiB", "3.0 GiB"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Report is missing %q:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestPercentDecimals(t *testing.T) {
+	a := NewAccumulator(1000000, 2)
+	for i := 0; i < 999999; i++ {
+		a.Add(1)
+	}
+	a.Add(2)
+	var buf bytes.Buffer
+	a.Print(&buf)
+	if report := buf.String(); !strings.Contains(report, "(0.00%)") {
+		t.Errorf("Default precision should round the rare bucket:\n%s", report)
+	}
+	a.PercentDecimals = 4
+	buf.Reset()
+	a.Print(&buf)
+	if report := buf.String(); !strings.Contains(report, "(0.0001%)") {
+		t.Errorf("Report is missing the rare bucket's percentage:\n%s", report)
+	}
+}
+
+func TestPrintEmpty(t *testing.T) {
+	for _, a := range []*Accumulator{
+		NewAccumulator(10, 5),
+		NewAccumulatorWithOptions(WithExactMode(), WithName("exact")),
+	} {
+		var buf bytes.Buffer
+		a.Print(&buf)
+		report := buf.String()
+		for _, unexpected := range []string{"NaN", "Inf"} {
+			if strings.Contains(report, unexpected) {
+				t.Errorf("Report contains %q:\n%s", unexpected, report)
+			}
+		}
+		if !strings.Contains(report, "n/a") {
+			t.Errorf("Report should show the undefined mean as n/a:\n%s", report)
+		}
+	}
+	is := IntStats{Format: FormatDuration}
+	if actual, correct := is.formatFraction(math.NaN()), "  n/a"; actual != correct {
+		t.Errorf("formatFraction: %q != %q", actual, correct)
+	}
+}
+
+func TestUnit(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithUnit("ms"), WithBuckets(2))
+	for _, v := range []int64{100, 200, 300} {
+		a.Add(v)
+	}
+	var buf bytes.Buffer
+	a.Print(&buf)
+	report := buf.String()
+	for _, expected := range []string{"Min            100 ms\n", "Max            300 ms\n", "Median         200 ms\n",
+		"Mean           200.000 ms\n", "100 ms -   200 ms"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Report is missing %q:\n%s", expected, report)
+		}
+	}
+	b := NewAccumulator(10, 2)
+	b.Add(100)
+	buf.Reset()
+	b.Print(&buf)
+	if strings.Contains(buf.String(), "ms") {
+		t.Errorf("Values shouldn't have a unit by default:\n%s", buf.String())
+	}
+}
+
+func TestPrintSections(t *testing.T) {
+	a := NewAccumulator(100, 10)
+	for _, v := range []int64{1, 2, 2, 3} {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	var all, sections bytes.Buffer
+	a.Print(&all)
+	is.PrintSections(&sections, SummarySection, DistributionSection, TopTermsSection)
+	if all.String() != sections.String() {
+		t.Errorf("Print should include every section:\n%s\n%s", all.String(), sections.String())
+	}
+	sections.Reset()
+	is.PrintSections(&sections, TopTermsSection, SummarySection)
+	report := sections.String()
+	if strings.Contains(report, "= Distribution") {
+		t.Errorf("The distribution shouldn't be printed:\n%s", report)
+	}
+	top, summary := strings.Index(report, "= Top Value Frequency"), strings.Index(report, "= Summary")
+	if top != 0 || summary < top {
+		t.Errorf("Sections should be printed in order:\n%s", report)
+	}
+}
+
+func TestShowCumulative(t *testing.T) {
+	a := NewAccumulator(100, 4)
+	a.ShowCumulative = true
+	for i := int64(0); i < 100; i++ {
+		a.Add(i)
+	}
+	// Outliers after the distribution
+	a.Add(1000)
+	a.Add(2000)
+	var buf bytes.Buffer
+	a.GetStats().PrintFrequencyDistribution(&buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if actual, correct := lines[1], "       0 -       24 :      25 (24.51%)       25 (24.51%)"; actual != correct {
+		t.Errorf("First bucket: %q != %q", actual, correct)
+	}
+	last := lines[len(lines)-2]
+	if !strings.HasSuffix(last, "     100 (98.04%)") {
+		t.Errorf("Last bucket before the outliers: %q", last)
+	}
+	if outliers := lines[len(lines)-1]; !strings.Contains(outliers, "     102 (100.00%)**") {
+		t.Errorf("The cumulative count should end at Count: %q", outliers)
+	}
+}
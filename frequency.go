@@ -0,0 +1,258 @@
+package cruncher
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// EvictionPolicy selects which value the term frequency stops tracking when
+// it's full and a new value is added
+type EvictionPolicy int
+
+const (
+	// EvictLeastFrequent replaces the least frequent value and the new value
+	// inherits its count, so values that become frequent late in the stream
+	// still surface (the Space-Saving algorithm)
+	EvictLeastFrequent EvictionPolicy = iota
+	// EvictLeastRecent replaces the value that was added least recently, so
+	// the values frequent in the recent portion of a long stream are
+	// tracked. Counts only include the values added since a value was last
+	// evicted.
+	EvictLeastRecent
+)
+
+// counter is the approximate number of times a value has been added
+type counter struct {
+	value int64
+	count int64
+	index int
+	// first is the order in which value started being tracked
+	first int64
+	// last is the order in which value was most recently added
+	last int64
+}
+
+// counterHeap orders counters from the first to the last to be evicted
+type counterHeap struct {
+	counters []*counter
+	policy   EvictionPolicy
+}
+
+func (h *counterHeap) Len() int { return len(h.counters) }
+func (h *counterHeap) Less(i, j int) bool {
+	if h.policy == EvictLeastRecent {
+		return h.counters[i].last < h.counters[j].last
+	}
+	return h.counters[i].count < h.counters[j].count
+}
+func (h *counterHeap) Swap(i, j int) {
+	h.counters[i], h.counters[j] = h.counters[j], h.counters[i]
+	h.counters[i].index = i
+	h.counters[j].index = j
+}
+
+func (h *counterHeap) Push(x interface{}) {
+	c := x.(*counter)
+	c.index = len(h.counters)
+	h.counters = append(h.counters, c)
+}
+
+func (h *counterHeap) Pop() interface{} {
+	old := h.counters
+	n := len(old)
+	c := old[n-1]
+	h.counters = old[0 : n-1]
+	return c
+}
+
+// spaceSaving tracks the most frequent values using a bounded number of
+// counters (the Space-Saving algorithm by Metwally, Agrawal and El Abbadi).
+// Once every counter is in use a new value replaces the least frequent
+// value and inherits its count, so values that become frequent late in the
+// stream still surface. Counts may be overestimated by at most the count of
+// the least frequent counter. With EvictLeastRecent the least recently
+// added value is replaced instead and its count is discarded.
+type spaceSaving struct {
+	capacity int
+	counters map[int64]*counter
+	heap     counterHeap
+	// seen is the number of values that started being tracked
+	seen int64
+	// added is the number of times addCount was called
+	added int64
+	// evicted is the number of values that stopped being tracked
+	evicted int64
+}
+
+func newSpaceSaving(capacity int) *spaceSaving {
+	return &spaceSaving{
+		capacity: capacity,
+		counters: make(map[int64]*counter),
+	}
+}
+
+// newSpaceSavingWithPolicy creates a spaceSaving evicting values by policy
+func newSpaceSavingWithPolicy(capacity int, policy EvictionPolicy) *spaceSaving {
+	s := newSpaceSaving(capacity)
+	s.heap.policy = policy
+	return s
+}
+
+// reserve allocates room for n values, up to the capacity, so the counters
+// don't need to grow as values are added
+func (s *spaceSaving) reserve(n int) {
+	if n > s.capacity {
+		n = s.capacity
+	}
+	if s.heap.Len() > 0 || n <= cap(s.heap.counters) {
+		return
+	}
+	s.counters = make(map[int64]*counter, n)
+	s.heap.counters = make([]*counter, 0, n)
+}
+
+func (s *spaceSaving) add(value int64) {
+	s.addCount(value, 1)
+}
+
+// addCount adds n occurrences of value
+func (s *spaceSaving) addCount(value, n int64) {
+	s.added++
+	if c, present := s.counters[value]; present {
+		c.count += n
+		c.last = s.added
+		heap.Fix(&s.heap, c.index)
+		return
+	}
+	s.seen++
+	if s.heap.Len() < s.capacity {
+		c := &counter{value: value, count: n, first: s.seen, last: s.added}
+		s.counters[value] = c
+		heap.Push(&s.heap, c)
+		return
+	}
+	// Replace the first value to be evicted
+	s.evicted++
+	c := s.heap.counters[0]
+	delete(s.counters, c.value)
+	if s.heap.policy == EvictLeastRecent {
+		c.count = 0
+	}
+	c.value = value
+	c.count += n
+	c.first = s.seen
+	c.last = s.added
+	s.counters[value] = c
+	heap.Fix(&s.heap, 0)
+}
+
+// merge adds the counts tracked by other, most frequent first when values
+// could be evicted
+func (s *spaceSaving) merge(other *spaceSaving) {
+	s.evicted += other.evicted
+	if s.heap.Len()+other.heap.Len() <= s.capacity {
+		// Every value fits so the order doesn't matter
+		for _, c := range other.heap.counters {
+			s.addCount(c.value, c.count)
+		}
+		return
+	}
+	counters := append([]*counter(nil), other.heap.counters...)
+	sort.Slice(counters, func(i, j int) bool { return counters[i].count > counters[j].count })
+	for _, c := range counters {
+		s.addCount(c.value, c.count)
+	}
+}
+
+// frequencies returns a newly allocated map of the tracked values to their
+// counts
+func (s *spaceSaving) frequencies() map[int64]int64 {
+	m := make(map[int64]int64, len(s.counters))
+	for v, c := range s.counters {
+		m[v] = c.count
+	}
+	return m
+}
+
+// firstSeen returns a newly allocated map of the tracked values to the order
+// in which they started being tracked, earlier values have smaller indexes
+func (s *spaceSaving) firstSeen() map[int64]int64 {
+	m := make(map[int64]int64, len(s.counters))
+	for v, c := range s.counters {
+		m[v] = c.first
+	}
+	return m
+}
+
+// quantize rounds value to the nearest multiple of step, halves are
+// rounded away from zero. Values are returned unchanged when step < 2 or
+// the multiple would overflow.
+func quantize(value, step int64) int64 {
+	if step < 2 {
+		return value
+	}
+	q := value / step * step
+	r := value - q
+	switch {
+	case r > 0 && r >= step-r && q <= math.MaxInt64-step:
+		q += step
+	case r < 0 && -r >= step+r && q >= math.MinInt64+step:
+		q -= step
+	}
+	return q
+}
+
+// ExactDistribution returns a copy of the stats with the frequency
+// distribution, into at most buckets buckets spanning Min to Max, and the
+// Median rebuilt from ValueFrequency. It's only valid when every distinct
+// value is tracked, fewer distinct values than the approximation window or
+// WithFrequencyCapacity were added and they weren't quantized, in which
+// case the result is exact rather than approximated from the window. The
+// stats are returned unchanged when any value was evicted, see
+// TermsEvicted, or the frequencies don't total Count, such as
+// WithoutTermFrequency.
+func (is IntStats) ExactDistribution(buckets int) IntStats {
+	values := make([]int64, 0, len(is.ValueFrequency))
+	var total int64
+	for v, f := range is.ValueFrequency {
+		values = append(values, v)
+		total += f
+	}
+	if is.Count == 0 || is.TermsEvicted > 0 || total != is.Count {
+		return is.clone()
+	}
+	sort.Sort(int64arr(values))
+	e := is.clone()
+	// Quantized values may fall just outside of Min to Max
+	lower, upper := is.Min, is.Max
+	if values[0] < lower {
+		lower = values[0]
+	}
+	if values[len(values)-1] > upper {
+		upper = values[len(values)-1]
+	}
+	if buckets < 1 {
+		buckets = 1
+	}
+	diff := span(lower, upper)
+	buckets = fitBuckets(diff, buckets)
+	e.Scale = LinearScale
+	e.FrequencyDistributionStartingValue = lower
+	e.BucketSize = bucketSizeFor(diff, buckets)
+	e.FrequencyDistribution = make([]int64, buckets)
+	e.BucketMin, e.BucketMax = nil, nil
+	e.OutlierBefore, e.OutlierAfter = 0, 0
+	// The lower middle value as for the Remedian
+	middle := (is.Count - 1) / 2
+	var cumulative int64
+	for _, v := range values {
+		f := is.ValueFrequency[v]
+		if cumulative <= middle && middle < cumulative+f {
+			e.Median = v
+		}
+		cumulative += f
+		e.FrequencyDistribution[e.bucketOffset(v)] += f
+	}
+	return e
+}
@@ -0,0 +1,282 @@
+package cruncher
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestLateFrequentValue(t *testing.T) {
+	a := NewAccumulator(10, 5)
+	for i := int64(0); i < 100; i++ {
+		a.Add(i)
+	}
+	for i := 0; i < 30; i++ {
+		a.Add(999)
+		a.Add(int64(1000 + i))
+	}
+	top := a.GetStats().GetTermFrequency(3)
+	if actual, correct := top[0].Value, int64(999); actual != correct {
+		t.Errorf("Most frequent value: %d != %d", actual, correct)
+	}
+	if top[0].Frequency < 30 {
+		t.Errorf("Frequency should be at least 30 but was %d", top[0].Frequency)
+	}
+	if actual, correct := len(a.GetStats().ValueFrequency), 10; actual != correct {
+		t.Errorf("Tracked values: %d != %d", actual, correct)
+	}
+}
+
+func TestSpaceSaving(t *testing.T) {
+	s := newSpaceSaving(3)
+	for _, v := range []int64{1, 1, 1, 2, 2, 3, 4, 4, 4, 4} {
+		s.add(v)
+	}
+	f := s.frequencies()
+	if actual, correct := len(f), 3; actual != correct {
+		t.Errorf("Tracked values: %d != %d", actual, correct)
+	}
+	if actual, correct := f[1], int64(3); actual != correct {
+		t.Errorf("Frequency of 1: %d != %d", actual, correct)
+	}
+	// 4 replaced 3, inheriting its count of 1
+	if actual, correct := f[4], int64(5); actual != correct {
+		t.Errorf("Frequency of 4: %d != %d", actual, correct)
+	}
+	if _, present := f[3]; present {
+		t.Errorf("3 should have been replaced")
+	}
+}
+
+func TestWithoutTermFrequency(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithoutTermFrequency(), WithBuckets(4))
+	for _, v := range []int64{4, 1, 3, 2, 2} {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	if actual, correct := len(is.GetTermFrequency(10)), 0; actual != correct {
+		t.Errorf("Terms: %d != %d", actual, correct)
+	}
+	if is.ValueFrequency != nil {
+		t.Errorf("ValueFrequency should be nil")
+	}
+	if is.Min != 1 || is.Max != 4 || is.Count != 5 || is.Median != 2 || is.Mean != 2.4 {
+		t.Errorf("Unexpected stats min %d max %d count %d median %d mean %f",
+			is.Min, is.Max, is.Count, is.Median, is.Mean)
+	}
+	if actual, correct := is.FrequencyDistribution, []int64{1, 2, 1, 1}; !reflect.DeepEqual(actual, correct) {
+		t.Errorf("Distribution: %v != %v", actual, correct)
+	}
+}
+
+func benchmarkAdd(b *testing.B, opts ...Option) {
+	a := NewAccumulatorWithOptions(opts...)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		a.Add(int64(i % 5000))
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	benchmarkAdd(b)
+}
+
+func BenchmarkAddWithoutTermFrequency(b *testing.B) {
+	benchmarkAdd(b, WithoutTermFrequency())
+}
+
+func TestSpaceSavingMerge(t *testing.T) {
+	a, b := newSpaceSaving(3), newSpaceSaving(3)
+	for _, v := range []int64{1, 1, 2, 3} {
+		a.add(v)
+	}
+	for _, v := range []int64{1, 4, 4, 4, 5} {
+		b.add(v)
+	}
+	a.merge(b)
+	f := a.frequencies()
+	if actual, correct := f[1], int64(3); actual != correct {
+		t.Errorf("Frequency of 1: %d != %d", actual, correct)
+	}
+	if actual, correct := f[4], int64(4); actual != correct {
+		t.Errorf("Frequency of 4: %d != %d", actual, correct)
+	}
+	if actual, correct := len(f), 3; actual != correct {
+		t.Errorf("Tracked values: %d != %d", actual, correct)
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	for _, c := range []struct{ value, step, correct int64 }{
+		{14, 10, 10}, {15, 10, 20}, {16, 10, 20}, {-14, 10, -10}, {-15, 10, -20},
+		{7, 1, 7}, {7, 0, 7}, {math.MaxInt64, 10, math.MaxInt64 - 7}, {math.MinInt64, 10, math.MinInt64 + 8},
+	} {
+		if actual := quantize(c.value, c.step); actual != c.correct {
+			t.Errorf("quantize(%d, %d): %d != %d", c.value, c.step, actual, c.correct)
+		}
+	}
+
+	a := NewAccumulatorWithOptions(WithQuantize(10))
+	for _, v := range []int64{1, 4, 6, 12, 14, 15, 21, 99} {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	correct := map[int64]int64{0: 2, 10: 3, 20: 2, 100: 1}
+	if !reflect.DeepEqual(is.ValueFrequency, correct) {
+		t.Errorf("ValueFrequency: %v != %v", is.ValueFrequency, correct)
+	}
+	if actual, correct := is.Max, int64(99); actual != correct {
+		t.Errorf("Max: %d != %d", actual, correct)
+	}
+}
+
+func benchmarkCardinality(b *testing.B, opts ...Option) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		a := NewAccumulatorWithOptions(opts...)
+		for v := int64(0); v < 20000; v++ {
+			a.Add(v)
+		}
+	}
+}
+
+func BenchmarkHighCardinality(b *testing.B) {
+	benchmarkCardinality(b, WithWindow(20000))
+}
+
+func BenchmarkHighCardinalityWithHint(b *testing.B) {
+	benchmarkCardinality(b, WithWindow(20000), WithExpectedCardinality(20000))
+}
+
+func TestExpectedCardinality(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithWindow(100), WithExpectedCardinality(1000))
+	if actual, correct := cap(a.frequency.heap.counters), 100; actual != correct {
+		t.Errorf("Reserved: %d != %d", actual, correct)
+	}
+	for v := int64(0); v < 50; v++ {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	if is.Count != 50 || is.Median != 24 || len(is.ValueFrequency) != 50 {
+		t.Errorf("Stats: %d %d %d", is.Count, is.Median, len(is.ValueFrequency))
+	}
+	exact := NewAccumulatorWithOptions(WithExactMode(), WithExpectedCardinality(1000))
+	if actual, correct := cap(exact.values), 1000; actual != correct {
+		t.Errorf("Reserved values: %d != %d", actual, correct)
+	}
+}
+
+func TestInsertionOrderTies(t *testing.T) {
+	values := []int64{7, 3, 9, 9}
+	a := NewAccumulator(100, 10)
+	b := NewAccumulatorWithOptions(WithInsertionOrderTies())
+	for _, v := range values {
+		a.Add(v)
+		b.Add(v)
+	}
+	byValue := a.GetStats().GetTermFrequency(3)
+	if actual, correct := byValue[1].Value, int64(3); actual != correct {
+		t.Errorf("Value tie-break: %d != %d", actual, correct)
+	}
+	byInsertion := b.GetStats().GetTermFrequency(3)
+	if actual, correct := byInsertion[0].Value, int64(9); actual != correct {
+		t.Errorf("Most frequent: %d != %d", actual, correct)
+	}
+	if actual, correct := byInsertion[1].Value, int64(7); actual != correct {
+		t.Errorf("Insertion tie-break: %d != %d", actual, correct)
+	}
+	if actual, correct := b.GetStats().GetTermFrequency(2)[1].Value, int64(7); actual != correct {
+		t.Errorf("Truncated insertion tie-break: %d != %d", actual, correct)
+	}
+}
+
+func TestEvictionPolicy(t *testing.T) {
+	tracked := func(policy EvictionPolicy) IntStats {
+		a := NewAccumulatorWithOptions(WithFrequencyCapacity(10), WithEvictionPolicy(policy))
+		// An early heavy hitter followed by a stream of distinct values
+		// where 7 is frequent
+		for i := 0; i < 500; i++ {
+			a.Add(5)
+		}
+		for v := int64(1000); v < 3000; v++ {
+			a.Add(v)
+			if v%3 == 0 {
+				a.Add(7)
+			}
+		}
+		return a.GetStats()
+	}
+	recent := tracked(EvictLeastRecent)
+	if actual, correct := len(recent.ValueFrequency), 10; actual != correct {
+		t.Errorf("Tracked values: %d != %d", actual, correct)
+	}
+	if actual, correct := recent.ValueFrequency[7], int64(666); actual != correct {
+		t.Errorf("Recent frequency of 7: %d != %d", actual, correct)
+	}
+	if _, present := recent.ValueFrequency[5]; present {
+		t.Errorf("The early value should have been evicted")
+	}
+	frequent := tracked(EvictLeastFrequent)
+	if actual, correct := len(frequent.ValueFrequency), 10; actual != correct {
+		t.Errorf("Tracked values: %d != %d", actual, correct)
+	}
+	if actual, correct := frequent.ValueFrequency[5], int64(500); actual != correct {
+		t.Errorf("Frequency of 5: %d != %d", actual, correct)
+	}
+}
+
+func TestExactDistribution(t *testing.T) {
+	// Adding the values in order skews the Remedian
+	values := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 10, 10, 10, 10, 10, 20, 30}
+	a := NewAccumulatorWithOptions(WithWindow(4), WithBuckets(5), WithFrequencyCapacity(100))
+	for _, v := range values {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	e := is.ExactDistribution(3)
+	if actual, correct := e.Median, int64(9); actual != correct {
+		t.Errorf("Median: %d != %d (approximated %d)", actual, correct, is.Median)
+	}
+	if actual, correct := e.BucketSize, int64(10); actual != correct {
+		t.Errorf("BucketSize: %d != %d", actual, correct)
+	}
+	for i, correct := range []int64{15, 1, 1} {
+		if actual := e.FrequencyDistribution[i]; actual != correct {
+			t.Errorf("Bucket %d: %d != %d", i, actual, correct)
+		}
+	}
+	if e.OutlierBefore != 0 || e.OutlierAfter != 0 {
+		t.Errorf("Outliers: %d, %d", e.OutlierBefore, e.OutlierAfter)
+	}
+	if err := (&Accumulator{intStats: e}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExactDistributionEvicted(t *testing.T) {
+	a := NewAccumulator(3, 3)
+	for v := int64(1); v <= 10; v++ {
+		a.Add(v)
+	}
+	is := a.GetStats()
+	if actual, correct := is.TermsEvicted, int64(7); actual != correct {
+		t.Errorf("TermsEvicted: %d != %d", actual, correct)
+	}
+	e := is.ExactDistribution(3)
+	if e.Min != 1 || e.Max != 10 {
+		t.Errorf("Range: %d - %d", e.Min, e.Max)
+	}
+	if actual, correct := e.Median, is.Median; actual != correct {
+		t.Errorf("Median: %d != %d", actual, correct)
+	}
+	if !equalInt64s(e.FrequencyDistribution, is.FrequencyDistribution) {
+		t.Errorf("Distribution: %v != %v", e.FrequencyDistribution, is.FrequencyDistribution)
+	}
+	m, err := MergeStats(is, is)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, correct := m.TermsEvicted, int64(14); actual != correct {
+		t.Errorf("Merged TermsEvicted: %d != %d", actual, correct)
+	}
+}
@@ -0,0 +1,33 @@
+package cruncher
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteGnuplot writes the distribution as a gnuplot data block, one line per
+// bucket of Buckets with the bucket's center and count, preceded by comment
+// lines suggesting how to plot it as a histogram.
+func (is IntStats) WriteGnuplot(w io.Writer) error {
+	title := is.Name
+	if title == "" {
+		title = "count"
+	}
+	header := fmt.Sprintf("# cruncher distribution of %d values\n"+
+		"# set style fill solid\n"+
+		"# set boxwidth %d\n"+
+		"# plot \"data\" using 1:2 with boxes title %q\n", is.Count, is.BucketSize, title)
+	if is.Scale == SymLogScale {
+		header += "# set logscale x 2\n"
+	}
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	for _, b := range is.Buckets() {
+		center := float64(b.LowerBound)/2 + float64(b.UpperBound)/2
+		if _, err := fmt.Fprintf(w, "%g %d\n", center, b.Count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
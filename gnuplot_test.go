@@ -0,0 +1,47 @@
+package cruncher
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteGnuplot(t *testing.T) {
+	a := NewAccumulator(100, 4)
+	for i := int64(0); i < 100; i++ {
+		a.Add(i)
+	}
+	is := a.GetStats()
+	var buf bytes.Buffer
+	if err := is.WriteGnuplot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var data []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			data = append(data, line)
+		}
+	}
+	if actual, correct := len(data), len(is.Buckets()); actual != correct {
+		t.Fatalf("Data lines: %d != %d\n%s", actual, correct, buf.String())
+	}
+	for i, line := range data {
+		columns := strings.Fields(line)
+		if len(columns) != 2 {
+			t.Fatalf("Line %d should have 2 columns: %q", i, line)
+		}
+		if _, err := strconv.ParseFloat(columns[0], 64); err != nil {
+			t.Errorf("Line %d center: %v", i, err)
+		}
+		if _, err := strconv.ParseInt(columns[1], 10, 64); err != nil {
+			t.Errorf("Line %d count: %v", i, err)
+		}
+	}
+	if actual, correct := data[0], "12 25"; actual != correct {
+		t.Errorf("First bucket: %q != %q", actual, correct)
+	}
+	if !strings.Contains(buf.String(), "with boxes") {
+		t.Errorf("Header should suggest a plot command:\n%s", buf.String())
+	}
+}
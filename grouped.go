@@ -0,0 +1,66 @@
+package cruncher
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// GroupedAccumulator accumulates a separate series of values per key, such
+// as per host or per endpoint. Each key's Accumulator is created, with the
+// key as its Name, by the first value added to it. Like Accumulator it
+// isn't safe for concurrent use.
+type GroupedAccumulator struct {
+	opts   []Option
+	groups map[string]*Accumulator
+}
+
+// NewGroupedAccumulator allocates a GroupedAccumulator whose per key
+// Accumulators are configured with opts
+func NewGroupedAccumulator(opts ...Option) *GroupedAccumulator {
+	return &GroupedAccumulator{
+		opts:   opts,
+		groups: make(map[string]*Accumulator),
+	}
+}
+
+// AddTo adds value to the series of key
+func (g *GroupedAccumulator) AddTo(key string, value int64) {
+	a, present := g.groups[key]
+	if !present {
+		a = NewAccumulatorWithOptions(append(g.opts[:len(g.opts):len(g.opts)], WithName(key))...)
+		g.groups[key] = a
+	}
+	a.Add(value)
+}
+
+// StatsFor returns the stats of the values added to key, they're empty if
+// none were
+func (g *GroupedAccumulator) StatsFor(key string) IntStats {
+	a, present := g.groups[key]
+	if !present {
+		return IntStats{Name: key}
+	}
+	return a.GetStats()
+}
+
+// Keys returns the keys values were added to in sorted order
+func (g *GroupedAccumulator) Keys() []string {
+	keys := make([]string, 0, len(g.groups))
+	for key := range g.groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PrintAll prints the report of each key in the order of Keys separated by
+// blank lines
+func (g *GroupedAccumulator) PrintAll(w io.Writer) {
+	for i, key := range g.Keys() {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		g.groups[key].Print(w)
+	}
+}
@@ -0,0 +1,44 @@
+package cruncher
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGroupedAccumulator(t *testing.T) {
+	g := NewGroupedAccumulator(WithWindow(100), WithBuckets(5))
+	for i := int64(0); i < 30; i++ {
+		g.AddTo("web", i)
+		if i%2 == 0 {
+			g.AddTo("db", i*10)
+		}
+		if i%3 == 0 {
+			g.AddTo("cache", -i)
+		}
+	}
+	keys := g.Keys()
+	if actual, correct := strings.Join(keys, ","), "cache,db,web"; actual != correct {
+		t.Errorf("Keys: %s != %s", actual, correct)
+	}
+	for key, correct := range map[string]int64{"web": 30, "db": 15, "cache": 10, "missing": 0} {
+		is := g.StatsFor(key)
+		if actual := is.Count; actual != correct {
+			t.Errorf("%s Count: %d != %d", key, actual, correct)
+		}
+		if actual := is.Name; actual != key {
+			t.Errorf("%s Name: %s", key, actual)
+		}
+	}
+	if actual, correct := g.StatsFor("db").Max, int64(280); actual != correct {
+		t.Errorf("db Max: %d != %d", actual, correct)
+	}
+	var buf bytes.Buffer
+	g.PrintAll(&buf)
+	report := buf.String()
+	for _, key := range keys {
+		if !strings.Contains(report, "= Summary ["+key+"]") {
+			t.Errorf("Report is missing %s:\n%s", key, report)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package cruncher
+
+import (
+	"math"
+	"math/bits"
+)
+
+// hllPrecision is the number of hash bits used to select a register, giving
+// 2^14 registers and a standard error of about 0.8%
+const hllPrecision = 14
+
+// hyperLogLog estimates the number of distinct values added using
+// fixed memory (Flajolet, Fusy, Gandouet and Meunier).
+type hyperLogLog struct {
+	registers []uint8
+}
+
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{registers: make([]uint8, 1<<hllPrecision)}
+}
+
+func (h *hyperLogLog) add(value int64) {
+	x := mix64(uint64(value))
+	register := x >> (64 - hllPrecision)
+	// The trailing bit guarantees the rank is bounded
+	rank := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1))) + 1
+	if rank > h.registers[register] {
+		h.registers[register] = rank
+	}
+}
+
+// merge combines the registers of other so the estimate covers the values
+// added to either sketch
+func (h *hyperLogLog) merge(other *hyperLogLog) {
+	for i, r := range other.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+}
+
+// estimate returns the approximate number of distinct values added
+func (h *hyperLogLog) estimate() uint64 {
+	m := float64(len(h.registers))
+	var sum float64
+	zeros := 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// Linear counting is more accurate for small cardinalities
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// mix64 is the splitmix64 finalizer, it spreads the bits of sequential
+// values across the full 64 bit range
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
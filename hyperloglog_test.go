@@ -0,0 +1,47 @@
+package cruncher
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDistinctEstimate(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithDistinctEstimate())
+	for i := 0; i < 1000000; i++ {
+		a.Add(rand.Int63n(10000) * 7919)
+	}
+	estimate := a.GetStats().DistinctEstimate
+	if math.Abs(float64(estimate)-10000) > 300 {
+		t.Errorf("DistinctEstimate %d isn't within 3%% of 10000", estimate)
+	}
+
+	b := NewAccumulator(1000, 10)
+	b.Add(1)
+	if actual, correct := b.GetStats().DistinctEstimate, uint64(0); actual != correct {
+		t.Errorf("DistinctEstimate should be off by default: %d != %d", actual, correct)
+	}
+}
+
+func TestHyperLogLogSmall(t *testing.T) {
+	h := newHyperLogLog()
+	for i := int64(0); i < 100; i++ {
+		h.add(i)
+		h.add(i)
+	}
+	if estimate := h.estimate(); estimate < 98 || estimate > 102 {
+		t.Errorf("Estimate %d should be close to 100", estimate)
+	}
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	a, b := newHyperLogLog(), newHyperLogLog()
+	for i := int64(0); i < 3000; i++ {
+		a.add(i)
+		b.add(i + 2000)
+	}
+	a.merge(b)
+	if estimate := a.estimate(); estimate < 4850 || estimate > 5150 {
+		t.Errorf("Estimate %d should be close to 5000", estimate)
+	}
+}
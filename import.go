@@ -0,0 +1,95 @@
+package cruncher
+
+import (
+	"fmt"
+	"math"
+)
+
+// ImportHistogram reconstructs IntStats from an existing histogram so it
+// can be printed or merged. bounds are the edges of equally sized buckets,
+// bucket i contains the values from bounds[i] up to but excluding
+// bounds[i+1]. counts either has one entry per bucket, len(bounds)-1, or
+// additionally starts and ends with the number of values below the first
+// and at or above the last bound, len(bounds)+1.
+// As the individual values aren't known Min and Max are derived from the
+// bounds of the outermost non-empty buckets while the Mean and StdDev
+// assume values are at the center of their buckets and the Median is
+// interpolated from the distribution.
+func ImportHistogram(bounds []int64, counts []int64) (IntStats, error) {
+	var is IntStats
+	if len(bounds) < 2 {
+		return is, fmt.Errorf("%w: a histogram requires at least 2 bounds but has %d", ErrInvalid, len(bounds))
+	}
+	switch len(counts) {
+	case len(bounds) - 1:
+	case len(bounds) + 1:
+		is.OutlierBefore, is.OutlierAfter = counts[0], counts[len(counts)-1]
+		counts = counts[1 : len(counts)-1]
+	default:
+		return is, fmt.Errorf("%w: a histogram with %d bounds requires %d or %d counts but has %d", ErrInvalid,
+			len(bounds), len(bounds)-1, len(bounds)+1, len(counts))
+	}
+	is.BucketSize = bounds[1] - bounds[0]
+	if is.BucketSize < 1 {
+		return is, fmt.Errorf("%w: histogram bounds must increase but %d follows %d", ErrInvalid, bounds[1], bounds[0])
+	}
+	for i := 2; i < len(bounds); i++ {
+		if bounds[i]-bounds[i-1] != is.BucketSize {
+			return is, fmt.Errorf("%w: histogram buckets must be the same size but %d - %d isn't %d wide", ErrInvalid,
+				bounds[i-1], bounds[i], is.BucketSize)
+		}
+	}
+	for _, count := range append([]int64{is.OutlierBefore, is.OutlierAfter}, counts...) {
+		if count < 0 {
+			return is, fmt.Errorf("%w: histogram counts can't be negative, %d", ErrInvalid, count)
+		}
+	}
+	is.FrequencyDistributionStartingValue = bounds[0]
+	is.FrequencyDistribution = append([]int64(nil), counts...)
+
+	// Moments use the center of each bucket, outliers are placed just
+	// outside of the distribution
+	var sum, squares float64
+	is.Count = is.OutlierBefore + is.OutlierAfter
+	sum = float64(is.OutlierBefore)*float64(bounds[0]-1) + float64(is.OutlierAfter)*float64(bounds[len(bounds)-1])
+	squares = float64(is.OutlierBefore)*math.Pow(float64(bounds[0]-1), 2) +
+		float64(is.OutlierAfter)*math.Pow(float64(bounds[len(bounds)-1]), 2)
+	first, last := -1, -1
+	for i, count := range counts {
+		if count == 0 {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+		center := float64(bounds[i]) + float64(is.BucketSize-1)/2
+		is.Count += count
+		sum += float64(count) * center
+		squares += float64(count) * center * center
+	}
+	if is.Count == 0 {
+		return is, nil
+	}
+	switch {
+	case is.OutlierBefore > 0:
+		is.Min = bounds[0] - 1
+	case first >= 0:
+		is.Min = bounds[first]
+	default:
+		is.Min = bounds[len(bounds)-1]
+	}
+	switch {
+	case is.OutlierAfter > 0:
+		is.Max = bounds[len(bounds)-1]
+	case last >= 0:
+		is.Max = bounds[last+1] - 1
+	default:
+		is.Max = bounds[0] - 1
+	}
+	is.Mean = sum / float64(is.Count)
+	is.Variance = math.Max(0, squares/float64(is.Count)-is.Mean*is.Mean)
+	is.StdDev = math.Sqrt(is.Variance)
+	is.Median = is.PercentileFromDistribution(0.5)
+	return is, nil
+}
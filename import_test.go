@@ -0,0 +1,61 @@
+package cruncher
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestImportHistogram(t *testing.T) {
+	bounds := []int64{0, 10, 20, 30, 40}
+	is, err := ImportHistogram(bounds, []int64{0, 10, 20, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, correct := is.Count, int64(40); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Min, int64(10); actual != correct {
+		t.Errorf("Min: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Max, int64(39); actual != correct {
+		t.Errorf("Max: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Mean, 24.5; actual != correct {
+		t.Errorf("Mean: %f != %f", actual, correct)
+	}
+	if actual, correct := is.Median, int64(25); actual != correct {
+		t.Errorf("Median: %d != %d", actual, correct)
+	}
+	var buf bytes.Buffer
+	is.Print(&buf)
+	if !strings.Contains(buf.String(), "      20 -       29 :      20 (50.00%)") {
+		t.Errorf("Unexpected report:\n%s", buf.String())
+	}
+
+	is, err = ImportHistogram(bounds, []int64{2, 0, 10, 20, 10, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if is.Count != 45 || is.OutlierBefore != 2 || is.OutlierAfter != 3 || is.Min != -1 || is.Max != 40 {
+		t.Errorf("Unexpected outliers count %d before %d after %d min %d max %d",
+			is.Count, is.OutlierBefore, is.OutlierAfter, is.Min, is.Max)
+	}
+}
+
+func TestImportHistogramInvalid(t *testing.T) {
+	for _, test := range []struct {
+		bounds, counts []int64
+	}{
+		{[]int64{0}, []int64{}},
+		{[]int64{0, 10, 20}, []int64{1}},
+		{[]int64{0, 10, 20}, []int64{1, 2, 3, 4, 5}},
+		{[]int64{0, 10, 25}, []int64{1, 2}},
+		{[]int64{10, 0}, []int64{1}},
+		{[]int64{0, 10, 20}, []int64{1, -2}},
+	} {
+		if _, err := ImportHistogram(test.bounds, test.counts); err == nil {
+			t.Errorf("ImportHistogram(%v, %v) should fail", test.bounds, test.counts)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cruncher
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// AddString parses a base 10 integer, ignoring surrounding whitespace, and
+// adds it to the data set. Values that can't be parsed return an error and
+// aren't added.
+func (a *Accumulator) AddString(s string) error {
+	value, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w %q: %w", ErrParse, s, err)
+	}
+	a.Add(value)
+	return nil
+}
+
+// AddChecked adds value if it's within the domain set WithDomain, otherwise
+// it returns an error and the stats are left untouched. Without a domain
+// it's equivalent to Add.
+func (a *Accumulator) AddChecked(value int64) error {
+	if a.domain && (value < a.domainMin || value > a.domainMax) {
+		return fmt.Errorf("%w: %d isn't from %d to %d", ErrOutOfDomain, value, a.domainMin, a.domainMax)
+	}
+	a.Add(value)
+	return nil
+}
+
+// Consume adds every value received from ch, blocking until ch is closed
+func (a *Accumulator) Consume(ch <-chan int64) {
+	for value := range ch {
+		a.Add(value)
+	}
+}
+
+// ConsumeContext adds every value received from ch until ch is closed,
+// returning nil, or ctx is cancelled, returning ctx.Err()
+func (a *Accumulator) ConsumeContext(ctx context.Context, ch <-chan int64) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case value, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			a.Add(value)
+		}
+	}
+}
+
+// ReadBinary adds fixed width 8 byte int64 values read from r in the byte
+// order until EOF, returning the number of values added. Trailing bytes
+// that don't form a whole value return an error wrapping ErrParse, values
+// read before it remain added.
+func (a *Accumulator) ReadBinary(r io.Reader, order binary.ByteOrder) (n int64, err error) {
+	br := bufio.NewReader(r)
+	var buf [8]byte
+	for {
+		read, err := io.ReadFull(br, buf[:])
+		switch err {
+		case nil:
+		case io.EOF:
+			return n, nil
+		case io.ErrUnexpectedEOF:
+			return n, fmt.Errorf("%w: %d trailing bytes aren't an int64", ErrParse, read)
+		default:
+			return n, err
+		}
+		a.Add(int64(order.Uint64(buf[:])))
+		n++
+	}
+}
@@ -0,0 +1,129 @@
+package cruncher
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAddString(t *testing.T) {
+	a := NewAccumulator(1000, 5)
+	for _, s := range []string{"12", " -7\n", "\t30 "} {
+		if err := a.AddString(s); err != nil {
+			t.Errorf("AddString(%q): %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "abc", "1.5", "99999999999999999999"} {
+		err := a.AddString(s)
+		if err == nil {
+			t.Errorf("AddString(%q) should fail", s)
+		} else if !strings.Contains(err.Error(), s) {
+			t.Errorf("Error should identify the input %q: %v", s, err)
+		}
+	}
+	is := a.GetStats()
+	if actual, correct := is.Count, int64(3); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Min, int64(-7); actual != correct {
+		t.Errorf("Min: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Max, int64(30); actual != correct {
+		t.Errorf("Max: %d != %d", actual, correct)
+	}
+}
+
+func TestAddChecked(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithDomain(0, 1000))
+	for _, v := range []int64{0, 5, -1, 1000, 1001, 500} {
+		err := a.AddChecked(v)
+		if valid := v >= 0 && v <= 1000; valid != (err == nil) {
+			t.Errorf("AddChecked(%d): %v", v, err)
+		}
+	}
+	is := a.GetStats()
+	if actual, correct := is.Count, int64(4); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	if is.Min != 0 || is.Max != 1000 {
+		t.Errorf("Range: %d - %d", is.Min, is.Max)
+	}
+	a.Add(-1)
+	if actual, correct := a.GetStats().Count, int64(5); actual != correct {
+		t.Errorf("Add should be lenient: %d != %d", actual, correct)
+	}
+	if err := NewAccumulator(10, 5).AddChecked(-1); err != nil {
+		t.Errorf("AddChecked without a domain: %v", err)
+	}
+}
+
+func TestConsume(t *testing.T) {
+	a := NewAccumulator(1000, 5)
+	ch := make(chan int64)
+	go func() {
+		for i := int64(0); i < 500; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+	a.Consume(ch)
+	if actual, correct := a.GetStats().Count, int64(500); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+
+	ch = make(chan int64, 10)
+	for i := int64(0); i < 10; i++ {
+		ch <- i
+	}
+	close(ch)
+	if err := a.ConsumeContext(context.Background(), ch); err != nil {
+		t.Errorf("ConsumeContext: %v", err)
+	}
+	if actual, correct := a.GetStats().Count, int64(510); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := a.ConsumeContext(ctx, make(chan int64)); err != context.Canceled {
+		t.Errorf("ConsumeContext: %v != %v", err, context.Canceled)
+	}
+}
+
+func TestReadBinary(t *testing.T) {
+	values := []int64{5, -3, math.MaxInt64, math.MinInt64, 1000}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		var buf bytes.Buffer
+		if err := binary.Write(&buf, order, values); err != nil {
+			t.Fatal(err)
+		}
+		a := NewAccumulator(100, 10)
+		n, err := a.ReadBinary(&buf, order)
+		if err != nil {
+			t.Fatalf("%s: %v", order, err)
+		}
+		if actual, correct := n, int64(len(values)); actual != correct {
+			t.Errorf("%s read: %d != %d", order, actual, correct)
+		}
+		is := a.GetStats()
+		if actual, correct := is.Count, int64(len(values)); actual != correct {
+			t.Errorf("%s Count: %d != %d", order, actual, correct)
+		}
+		if actual, correct := is.Sum.Int64(), int64(1001); actual != correct {
+			t.Errorf("%s Sum: %d != %d", order, actual, correct)
+		}
+	}
+
+	a := NewAccumulator(100, 10)
+	n, err := a.ReadBinary(bytes.NewReader(make([]byte, 12)), binary.LittleEndian)
+	if !errors.Is(err, ErrParse) {
+		t.Errorf("Trailing bytes: %v isn't %v", err, ErrParse)
+	}
+	if actual, correct := n, int64(1); actual != correct {
+		t.Errorf("Read before trailing bytes: %d != %d", actual, correct)
+	}
+}
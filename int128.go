@@ -0,0 +1,52 @@
+package cruncher
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// int128 is a signed 128 bit integer used to total int64 values without
+// overflowing
+type int128 struct {
+	hi int64
+	lo uint64
+}
+
+// add adds a sign extended int64 to the total
+func (i *int128) add(value int64) {
+	var carry uint64
+	i.lo, carry = bits.Add64(i.lo, uint64(value), 0)
+	i.hi += int64(carry) + value>>63
+}
+
+// sub subtracts a sign extended int64 from the total
+func (i *int128) sub(value int64) {
+	var borrow uint64
+	i.lo, borrow = bits.Sub64(i.lo, uint64(value), 0)
+	i.hi -= int64(borrow) + value>>63
+}
+
+// addInt128 adds another 128 bit total
+func (i *int128) addInt128(other int128) {
+	var carry uint64
+	i.lo, carry = bits.Add64(i.lo, other.lo, 0)
+	i.hi += other.hi + int64(carry)
+}
+
+// Float64 returns the nearest float64 to the total
+func (i int128) Float64() float64 {
+	if i.hi == int64(i.lo)>>63 {
+		// The total fits in an int64, small negative totals would otherwise
+		// cancel out as hi*2^64 and lo are both rounded
+		return float64(int64(i.lo))
+	}
+	return float64(i.hi)*math.Exp2(64) + float64(i.lo)
+}
+
+// Big returns the total as a newly allocated big.Int
+func (i int128) Big() *big.Int {
+	b := big.NewInt(i.hi)
+	b.Lsh(b, 64)
+	return b.Add(b, new(big.Int).SetUint64(i.lo))
+}
@@ -0,0 +1,22 @@
+package cruncher
+
+// OnInterval registers fn to be called with a Snapshot of the stats after
+// every n values are added. fn is called synchronously by Add so, like the
+// rest of the Accumulator, it's not invoked concurrently. A non-positive n
+// or nil fn removes the callback.
+func (a *Accumulator) OnInterval(n int, fn func(IntStats)) {
+	if n <= 0 || fn == nil {
+		a.interval, a.onInterval = 0, nil
+		return
+	}
+	a.interval, a.onInterval = int64(n), fn
+}
+
+// OnRemedianFlush registers fn to be called whenever a block of the
+// Remedian is full and collapses into its median, which is added to the
+// next level. level is the block's level, 0 for the block of added values,
+// and min, max and median summarize the block. It's useful to observe how
+// the median converges. A nil fn removes the callback.
+func (a *Accumulator) OnRemedianFlush(fn func(level int, min, max, median int64)) {
+	a.onRemedianFlush = fn
+}
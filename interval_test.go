@@ -0,0 +1,69 @@
+package cruncher
+
+import "testing"
+
+func TestOnInterval(t *testing.T) {
+	a := NewAccumulator(1000, 10)
+	var counts []int64
+	a.OnInterval(100, func(is IntStats) {
+		counts = append(counts, is.Count)
+	})
+	for i := int64(0); i < 1000; i++ {
+		a.Add(i)
+	}
+	a.Summarize()
+	if actual, correct := len(counts), 10; actual != correct {
+		t.Fatalf("Callbacks: %d != %d", actual, correct)
+	}
+	for i, count := range counts {
+		if actual, correct := count, int64(100*(i+1)); actual != correct {
+			t.Errorf("Count %d: %d != %d", i, actual, correct)
+		}
+	}
+	// Snapshots before the window filled shouldn't have fixed the distribution
+	is := a.GetStats()
+	if actual, correct := is.OutlierAfter, int64(0); actual != correct {
+		t.Errorf("OutlierAfter: %d != %d", actual, correct)
+	}
+
+	a.OnInterval(0, nil)
+	a.Add(1)
+	if actual, correct := len(counts), 10; actual != correct {
+		t.Errorf("Callbacks after removal: %d != %d", actual, correct)
+	}
+}
+
+func TestGetStatsCopy(t *testing.T) {
+	a := NewAccumulator(10, 2)
+	for i := int64(0); i < 20; i++ {
+		a.Add(i % 10)
+	}
+	is := a.GetStats()
+	before := append([]int64(nil), is.FrequencyDistribution...)
+	for i := int64(0); i < 20; i++ {
+		a.Add(i % 10)
+	}
+	if !equalInt64s(before, is.FrequencyDistribution) {
+		t.Errorf("Distribution changed after GetStats: %v != %v", is.FrequencyDistribution, before)
+	}
+}
+
+func TestOnRemedianFlush(t *testing.T) {
+	a := NewAccumulator(10, 5)
+	flushes := map[int]int{}
+	a.OnRemedianFlush(func(level int, min, max, median int64) {
+		flushes[level]++
+		if min > median || median > max {
+			t.Errorf("Level %d flushed %d <= %d <= %d", level, min, median, max)
+		}
+	})
+	// Blocks hold 11 values before they collapse
+	for i := int64(0); i < 11*11*2; i++ {
+		a.Add(i)
+	}
+	if flushes[0] != 22 || flushes[1] != 2 || len(flushes) != 2 {
+		t.Errorf("Flushes: %v", flushes)
+	}
+	a.OnRemedianFlush(nil)
+	a.Add(0)
+}
@@ -0,0 +1,50 @@
+package cruncher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonTopTerms is the number of most frequent values WriteJSON includes
+const jsonTopTerms = 100
+
+// WriteJSON streams the stats as a JSON object. Unlike json.Marshal, which
+// includes every tracked value in ValueFrequency, only the most frequent
+// values are written as a TopTerms list so the output remains small for
+// data with many distinct values.
+func (is IntStats) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	fields := []struct {
+		name  string
+		value interface{}
+	}{
+		{"Name", is.Name},
+		{"Min", is.Min},
+		{"Max", is.Max},
+		{"Count", is.Count},
+		{"Sum", is.Sum},
+		{"Mean", is.Mean},
+		{"Variance", is.Variance},
+		{"StdDev", is.StdDev},
+		{"Median", is.Median},
+		{"BucketSize", is.BucketSize},
+		{"FrequencyDistributionStartingValue", is.FrequencyDistributionStartingValue},
+		{"OutlierBefore", is.OutlierBefore},
+		{"OutlierAfter", is.OutlierAfter},
+		{"FrequencyDistribution", is.FrequencyDistribution},
+		{"TopTerms", is.GetTermFrequency(jsonTopTerms)},
+	}
+	separator := "{"
+	for _, f := range fields {
+		if _, err := fmt.Fprintf(w, "%s%q:", separator, f.name); err != nil {
+			return err
+		}
+		if err := enc.Encode(f.value); err != nil {
+			return err
+		}
+		separator = ","
+	}
+	_, err := io.WriteString(w, "}\n")
+	return err
+}
@@ -0,0 +1,47 @@
+package cruncher
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	a := NewAccumulator(1000, 10)
+	for i := int64(0); i < 5000; i++ {
+		a.Add(i % 500)
+	}
+	var buf bytes.Buffer
+	if err := a.GetStats().WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		t.Fatalf("Invalid JSON:\n%s", buf.String())
+	}
+	var decoded struct {
+		Count                 int64
+		FrequencyDistribution []int64
+		TopTerms              PairList
+		ValueFrequency        map[int64]int64
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if actual, correct := decoded.Count, int64(5000); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	if actual, correct := len(decoded.FrequencyDistribution), 10; actual != correct {
+		t.Errorf("Distribution: %d != %d", actual, correct)
+	}
+	if actual, correct := len(decoded.TopTerms), jsonTopTerms; actual != correct {
+		t.Errorf("Top terms: %d != %d", actual, correct)
+	}
+	if decoded.ValueFrequency != nil {
+		t.Errorf("The full frequency map shouldn't be written")
+	}
+
+	buf.Reset()
+	if err := (IntStats{}).WriteJSON(&buf); err != nil || !json.Valid(buf.Bytes()) {
+		t.Errorf("Empty stats should be valid JSON: %v\n%s", err, buf.String())
+	}
+}
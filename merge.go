@@ -0,0 +1,348 @@
+package cruncher
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// MergeStats combines two summarized IntStats, such as those loaded from
+// JSON, into a single IntStats without requiring the original Accumulators.
+// Count, Min, Max, Sum, Mean, Variance, the outliers and ValueFrequency are combined exactly.
+// Frequency distributions with different linear layouts are resampled
+// onto a common layout spanning both, see Resample, which approximates how
+// the values are spread within the buckets. An error is returned when the
+// distributions use different scales.
+// The Median can't be merged exactly, the result is a best-effort estimate
+// computed as the count weighted mean of the two medians, unless both
+// Samples hold every value, as in exact mode. Then the Samples are merged
+// and the Median is the lower middle value.
+func MergeStats(a, b IntStats) (IntStats, error) {
+	if b.Count == 0 {
+		a.Skipped += b.Skipped
+		a.NonFinite += b.NonFinite
+		return a, nil
+	}
+	if a.Count == 0 {
+		b.Name = a.Name
+		b.Skipped += a.Skipped
+		b.NonFinite += a.NonFinite
+		return b, nil
+	}
+	m := IntStats{
+		Name:          a.Name,
+		Skipped:       a.Skipped + b.Skipped,
+		NonFinite:     a.NonFinite + b.NonFinite,
+		TermsEvicted:  a.TermsEvicted + b.TermsEvicted,
+		Min:           a.Min,
+		Max:           a.Max,
+		Count:         a.Count + b.Count,
+		NegativeCount: a.NegativeCount + b.NegativeCount,
+		ZeroCount:     a.ZeroCount + b.ZeroCount,
+		PositiveCount: a.PositiveCount + b.PositiveCount,
+		OutlierBefore: a.OutlierBefore + b.OutlierBefore,
+		OutlierAfter:  a.OutlierAfter + b.OutlierAfter,
+	}
+	if b.Min < m.Min {
+		m.Min = b.Min
+	}
+	if b.Max > m.Max {
+		m.Max = b.Max
+	}
+	m.SecondMin, m.SecondMax = secondExtremes(a, b)
+	if a.SampleRate == b.SampleRate {
+		m.SampleRate = a.SampleRate
+	}
+	m.IsMonotonicIncreasing, m.IsMonotonicDecreasing = monotonic(a, b)
+	wa := float64(a.Count) / float64(m.Count)
+	wb := float64(b.Count) / float64(m.Count)
+	m.Mean = a.Mean*wa + b.Mean*wb
+	// Chan et al. parallel variance
+	delta := b.Mean - a.Mean
+	m.Variance = a.Variance*wa + b.Variance*wb + delta*delta*wa*wb
+	m.StdDev = math.Sqrt(m.Variance)
+	m.Median = int64(math.Round(float64(a.Median)*wa + float64(b.Median)*wb))
+	if a.Sum != nil && b.Sum != nil {
+		m.Sum = new(big.Int).Add(a.Sum, b.Sum)
+	}
+	if int64(len(a.Sample)) == a.Count && int64(len(b.Sample)) == b.Count {
+		m.Sample = make([]int64, len(a.Sample)+len(b.Sample))
+		mergeSorted(m.Sample, a.Sample, b.Sample)
+		m.Median = middle(m.Sample, false)
+	}
+
+	switch {
+	case len(b.FrequencyDistribution) == 0:
+		m.setDistribution(a)
+	case len(a.FrequencyDistribution) == 0:
+		m.setDistribution(b)
+	case a.SameLayout(b):
+		m.setDistribution(a)
+		m.mergeBucketRange(b)
+		for i, v := range b.FrequencyDistribution {
+			m.FrequencyDistribution[i] += v
+		}
+	case a.Scale == LinearScale && b.Scale == LinearScale:
+		a, b = commonLayout(a, b)
+		m.setDistribution(a)
+		m.mergeBucketRange(b)
+		for i, v := range b.FrequencyDistribution {
+			m.FrequencyDistribution[i] += v
+		}
+	default:
+		return IntStats{}, layoutError(a, b)
+	}
+
+	m.ValueFrequency = make(map[int64]int64, len(a.ValueFrequency)+len(b.ValueFrequency))
+	for k, v := range a.ValueFrequency {
+		m.ValueFrequency[k] = v
+	}
+	for k, v := range b.ValueFrequency {
+		m.ValueFrequency[k] += v
+	}
+	return m, nil
+}
+
+// SameLayout reports whether other's frequency distribution has the same
+// scale, number of buckets, bucket size and starting value, and so can be merged
+// or compared bucket by bucket.
+func (is IntStats) SameLayout(other IntStats) bool {
+	return is.Scale == other.Scale &&
+		len(is.FrequencyDistribution) == len(other.FrequencyDistribution) &&
+		is.BucketSize == other.BucketSize &&
+		is.FrequencyDistributionStartingValue == other.FrequencyDistributionStartingValue
+}
+
+// commonLayout resamples the linear distributions of a and b onto the same
+// layout covering both of their ranges with as many buckets as the larger.
+// Outliers aren't included in the range and remain outliers.
+func commonLayout(a, b IntStats) (IntStats, IntStats) {
+	start := a.FrequencyDistributionStartingValue
+	if b.FrequencyDistributionStartingValue < start {
+		start = b.FrequencyDistributionStartingValue
+	}
+	_, end := a.bucketBounds(len(a.FrequencyDistribution) - 1)
+	if _, bEnd := b.bucketBounds(len(b.FrequencyDistribution) - 1); bEnd > end {
+		end = bEnd
+	}
+	n := len(a.FrequencyDistribution)
+	if len(b.FrequencyDistribution) > n {
+		n = len(b.FrequencyDistribution)
+	}
+	size := bucketSizeFor(span(start, end), fitBuckets(span(start, end), n))
+	n = int(span(start, end)/uint64(size) + 1)
+	return a.resampleTo(start, size, n), b.resampleTo(start, size, n)
+}
+
+func layoutError(a, b IntStats) error {
+	return fmt.Errorf("%w (%d buckets of %d from %d and %d buckets of %d from %d)", ErrIncompatibleLayout,
+		len(a.FrequencyDistribution), a.BucketSize, a.FrequencyDistributionStartingValue,
+		len(b.FrequencyDistribution), b.BucketSize, b.FrequencyDistributionStartingValue)
+}
+
+// Merge adds the data accumulated by other to a, as if every value added to
+// other had been added to a. other isn't modified. The frequency
+// distributions must have the same layout, see SameLayout, unless one of
+// them hasn't been fixed yet. The median, term frequencies and sample remain
+// approximations. An Accumulator in exact mode can only merge another whose
+// values are all retained, one in exact mode or one that hasn't collapsed
+// any values into Remedian medians yet, otherwise ErrNotExact is returned.
+func (a *Accumulator) Merge(other *Accumulator) error {
+	aFixed := len(a.intStats.FrequencyDistribution) > 0
+	otherFixed := len(other.intStats.FrequencyDistribution) > 0
+	if aFixed && otherFixed && !a.intStats.SameLayout(other.intStats) {
+		return layoutError(a.intStats, other.intStats)
+	}
+	if a.exact && !other.exact && len(other.remedians) > 1 {
+		return fmt.Errorf("%w: %d values were collapsed into medians", ErrNotExact, other.intStats.Count)
+	}
+	a.intStats.Skipped += other.intStats.Skipped
+	a.intStats.NonFinite += other.intStats.NonFinite
+	if other.intStats.Count == 0 {
+		return nil
+	}
+
+	if a.intStats.Count == 0 {
+		a.intStats.Min, a.intStats.Max = other.intStats.Min, other.intStats.Max
+		a.intStats.SecondMin, a.intStats.SecondMax = other.intStats.SecondMin, other.intStats.SecondMax
+		a.intStats.IsMonotonicIncreasing = other.intStats.IsMonotonicIncreasing
+		a.intStats.IsMonotonicDecreasing = other.intStats.IsMonotonicDecreasing
+	} else {
+		a.intStats.SecondMin, a.intStats.SecondMax = secondExtremes(a.intStats, other.intStats)
+		a.intStats.IsMonotonicIncreasing, a.intStats.IsMonotonicDecreasing = monotonic(a.intStats, other.intStats)
+		if other.intStats.Min < a.intStats.Min {
+			a.intStats.Min = other.intStats.Min
+		}
+		if other.intStats.Max > a.intStats.Max {
+			a.intStats.Max = other.intStats.Max
+		}
+	}
+	count := a.intStats.Count + other.intStats.Count
+	delta := other.runningMean - a.runningMean
+	a.m2 += other.m2 + delta*delta*float64(a.intStats.Count)*float64(other.intStats.Count)/float64(count)
+	a.runningMean += delta * float64(other.intStats.Count) / float64(count)
+	a.intStats.Count = count
+	a.previous = other.previous
+	a.intStats.NegativeCount += other.intStats.NegativeCount
+	a.intStats.ZeroCount += other.intStats.ZeroCount
+	a.intStats.PositiveCount += other.intStats.PositiveCount
+	a.total.addInt128(other.total)
+
+	switch {
+	case aFixed && otherFixed:
+		a.intStats.mergeBucketRange(other.intStats)
+		for i, v := range other.intStats.FrequencyDistribution {
+			a.intStats.FrequencyDistribution[i] += v
+		}
+		a.intStats.OutlierBefore += other.intStats.OutlierBefore
+		a.intStats.OutlierAfter += other.intStats.OutlierAfter
+		if a.coarsens() {
+			a.outliers = append(a.outliers, other.outliers...)
+		}
+	case aFixed:
+		for _, v := range other.pending() {
+			a.incrementFrequencyDistribution(v)
+		}
+	case otherFixed:
+		a.intStats.setDistribution(other.intStats)
+		if !a.bucketRange {
+			a.intStats.BucketMin, a.intStats.BucketMax = nil, nil
+		}
+		a.intStats.OutlierBefore = other.intStats.OutlierBefore
+		a.intStats.OutlierAfter = other.intStats.OutlierAfter
+		a.outliers = nil
+		if a.coarsens() {
+			a.outliers = append(a.outliers, other.outliers...)
+		}
+		for _, v := range a.pending() {
+			a.incrementFrequencyDistribution(v)
+		}
+	case count >= int64(a.appoximationWindow):
+		// Lay out the distribution while the values of both are pending,
+		// merging the Remedians could collapse them into medians
+		a.initializeFrequencyDistribution()
+		for _, v := range other.pending() {
+			a.incrementFrequencyDistribution(v)
+		}
+	}
+	a.coarsenIfNeeded()
+
+	switch {
+	case a.exact && other.exact:
+		a.values = append(a.values, other.values...)
+	case a.exact:
+		// other hasn't collapsed any values, so the first level holds them all
+		a.values = append(a.values, other.pending()...)
+	case other.exact:
+		for _, v := range other.values {
+			a.pushMedianValue(0, v)
+		}
+	default:
+		for level, values := range other.remedians {
+			for _, v := range values {
+				a.pushMedianValue(level, v)
+			}
+		}
+	}
+	if a.frequency != nil && other.frequency != nil {
+		a.frequency.merge(other.frequency)
+		if other.dense != nil {
+			other.dense.each(func(value, count int64) {
+				if a.dense == nil || !a.dense.addCount(value, count) {
+					a.frequency.addCount(value, count)
+				}
+			})
+		}
+	}
+	if other.weighted != nil {
+		if a.weighted == nil {
+			a.weighted = newSpaceSaving(a.termCapacity())
+		}
+		a.weighted.merge(other.weighted)
+	}
+	if a.distinct != nil && other.distinct != nil {
+		a.distinct.merge(other.distinct)
+	}
+	if a.digest != nil && other.digest != nil {
+		a.digest.merge(other.digest)
+	}
+	if a.reservoir != nil && other.reservoir != nil {
+		a.reservoir.merge(other.reservoir)
+	}
+	return nil
+}
+
+// mergeResampled merges other into a like Merge, except linear
+// distributions with different layouts are first resampled onto a common
+// layout spanning both, as MergeStats does, rather than being an error.
+// other isn't modified, and neither is a when an error is returned.
+func (a *Accumulator) mergeResampled(other *Accumulator) error {
+	if len(a.intStats.FrequencyDistribution) == 0 || len(other.intStats.FrequencyDistribution) == 0 ||
+		a.intStats.Scale != LinearScale || other.intStats.Scale != LinearScale ||
+		a.intStats.SameLayout(other.intStats) {
+		return a.Merge(other)
+	}
+	original := a.intStats
+	resampled := *other
+	a.intStats, resampled.intStats = commonLayout(a.intStats, other.intStats)
+	if err := a.Merge(&resampled); err != nil {
+		// Merge fails before changing anything but the layout was replaced
+		a.intStats = original
+		return err
+	}
+	return nil
+}
+
+// secondExtremes returns the second smallest and second largest values of
+// the combined non-empty stats a and b
+func secondExtremes(a, b IntStats) (secondMin, secondMax int64) {
+	var extremes []int64
+	for _, is := range []IntStats{a, b} {
+		extremes = append(extremes, is.Min, is.Max)
+		if is.Count > 2 {
+			extremes = append(extremes, is.SecondMin, is.SecondMax)
+		} else if is.Count == 1 {
+			// Min and Max are the same value
+			extremes = extremes[:len(extremes)-1]
+		}
+	}
+	sort.Slice(extremes, func(i, j int) bool { return extremes[i] < extremes[j] })
+	return extremes[1], extremes[len(extremes)-2]
+}
+
+// monotonic reports whether the values of the non-empty stats a followed by
+// those of b are in non-decreasing and non-increasing order
+func monotonic(a, b IntStats) (increasing, decreasing bool) {
+	increasing = a.IsMonotonicIncreasing && b.IsMonotonicIncreasing && a.Max <= b.Min
+	decreasing = a.IsMonotonicDecreasing && b.IsMonotonicDecreasing && a.Min >= b.Max
+	return increasing, decreasing
+}
+
+// setDistribution copies the bucket layout and counts from is
+func (m *IntStats) setDistribution(is IntStats) {
+	m.Scale = is.Scale
+	m.BucketSize = is.BucketSize
+	m.FrequencyDistributionStartingValue = is.FrequencyDistributionStartingValue
+	m.FrequencyDistribution = append([]int64(nil), is.FrequencyDistribution...)
+	m.BucketMin, m.BucketMax = nil, nil
+	if is.BucketMin != nil {
+		m.BucketMin = append([]int64(nil), is.BucketMin...)
+		m.BucketMax = append([]int64(nil), is.BucketMax...)
+	}
+}
+
+// CombineInto merges a into dst, it's Merge with the receiver reversed for
+// folding many Accumulators, such as per goroutine shards, into one:
+//
+//	for _, shard := range shards {
+//		if err := shard.CombineInto(total); err != nil {
+//			...
+//		}
+//	}
+//
+// Values are added to dst's term frequency and Remedian in place without
+// intermediate copies. a is left unchanged.
+func (a *Accumulator) CombineInto(dst *Accumulator) error {
+	return dst.Merge(a)
+}
@@ -0,0 +1,322 @@
+package cruncher
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func loadStats(t *testing.T, values ...int64) IntStats {
+	a := NewAccumulator(1000, 4)
+	for _, v := range values {
+		a.Add(v)
+	}
+	data, err := json.Marshal(a.GetStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var is IntStats
+	if err := json.Unmarshal(data, &is); err != nil {
+		t.Fatal(err)
+	}
+	return is
+}
+
+func TestMergeStats(t *testing.T) {
+	a := loadStats(t, 1, 2, 3, 4, 4, 5, 6, 8)
+	b := loadStats(t, 1, 4, 7, 8)
+	m, err := MergeStats(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, correct := m.Count, int64(12); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	if actual, correct := m.Min, int64(1); actual != correct {
+		t.Errorf("Min: %d != %d", actual, correct)
+	}
+	if actual, correct := m.Max, int64(8); actual != correct {
+		t.Errorf("Max: %d != %d", actual, correct)
+	}
+	if actual, correct := m.Mean, float64(53)/12; math.Abs(actual-correct) > 1e-9 {
+		t.Errorf("Mean: %f != %f", actual, correct)
+	}
+	var squares float64
+	for _, v := range []float64{1, 2, 3, 4, 4, 5, 6, 8, 1, 4, 7, 8} {
+		squares += (v - 53.0/12) * (v - 53.0/12)
+	}
+	if actual, correct := m.Variance, squares/12; math.Abs(actual-correct) > 1e-9 {
+		t.Errorf("Variance: %f != %f", actual, correct)
+	}
+	if actual, correct := m.Sum.Int64(), int64(53); actual != correct {
+		t.Errorf("Sum: %d != %d", actual, correct)
+	}
+	if actual, correct := m.ValueFrequency[4], int64(3); actual != correct {
+		t.Errorf("Frequency of 4: %d != %d", actual, correct)
+	}
+	var total int64
+	for _, v := range m.FrequencyDistribution {
+		total += v
+	}
+	if actual, correct := total, m.Count; actual != correct {
+		t.Errorf("Distribution total: %d != %d", actual, correct)
+	}
+}
+
+func TestMergeStatsIncompatible(t *testing.T) {
+	a := loadStats(t, 1, 2, 3, 4)
+	b := loadStats(t, 100, 200, 300, 400)
+	b.Scale = SymLogScale
+	if _, err := MergeStats(a, b); err == nil {
+		t.Errorf("Merging different scales should fail")
+	}
+	if m, err := MergeStats(a, IntStats{}); err != nil || m.Count != a.Count {
+		t.Errorf("Merging with empty stats should return the original: %v", err)
+	}
+}
+
+func TestSameLayout(t *testing.T) {
+	a := IntStats{BucketSize: 10, FrequencyDistributionStartingValue: 5, FrequencyDistribution: make([]int64, 4)}
+	b := IntStats{BucketSize: 10, FrequencyDistributionStartingValue: 5, FrequencyDistribution: []int64{1, 2, 3, 4}}
+	if !a.SameLayout(b) || !b.SameLayout(a) {
+		t.Errorf("Layouts should match")
+	}
+	for _, c := range []IntStats{
+		{BucketSize: 5, FrequencyDistributionStartingValue: 5, FrequencyDistribution: make([]int64, 4)},
+		{BucketSize: 10, FrequencyDistributionStartingValue: 0, FrequencyDistribution: make([]int64, 4)},
+		{BucketSize: 10, FrequencyDistributionStartingValue: 5, FrequencyDistribution: make([]int64, 5)},
+	} {
+		if a.SameLayout(c) {
+			t.Errorf("Layouts shouldn't match %d %d %d", c.BucketSize, c.FrequencyDistributionStartingValue, len(c.FrequencyDistribution))
+		}
+	}
+	a.Count, b.Count = 1, 1
+	b.Scale = SymLogScale
+	if _, err := MergeStats(a, b); err == nil || !strings.Contains(err.Error(), "different layouts") {
+		t.Errorf("MergeStats should report the incompatible layout: %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	opts := []Option{WithWindow(100), WithBuckets(10), WithDistributionStart(0)}
+	a := NewAccumulatorWithOptions(opts...)
+	b := NewAccumulatorWithOptions(opts...)
+	sequential := NewAccumulatorWithOptions(opts...)
+	for _, acc := range []*Accumulator{a, b} {
+		acc.Add(999)
+		sequential.Add(999)
+		for i := 0; i < 500; i++ {
+			v := rand.Int63n(1000)
+			acc.Add(v)
+			sequential.Add(v)
+		}
+	}
+	distribution := append([]int64(nil), a.intStats.FrequencyDistribution...)
+	for i, v := range b.intStats.FrequencyDistribution {
+		distribution[i] += v
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	merged, correct := a.GetStats(), sequential.GetStats()
+	if merged.Count != correct.Count || merged.Min != correct.Min || merged.Max != correct.Max ||
+		merged.Sum.Cmp(correct.Sum) != 0 {
+		t.Errorf("Merged count %d min %d max %d sum %s != %d %d %d %s", merged.Count, merged.Min, merged.Max, merged.Sum,
+			correct.Count, correct.Min, correct.Max, correct.Sum)
+	}
+	if math.Abs(merged.Mean-correct.Mean) > 1e-9 || math.Abs(merged.Variance-correct.Variance) > 1e-6 {
+		t.Errorf("Merged mean %f variance %f != %f %f", merged.Mean, merged.Variance, correct.Mean, correct.Variance)
+	}
+	if !equalInt64s(merged.FrequencyDistribution, distribution) {
+		t.Errorf("Distribution: %v != %v", merged.FrequencyDistribution, distribution)
+	}
+	if math.Abs(float64(merged.Median-correct.Median)) > 100 {
+		t.Errorf("Median: %d != %d", merged.Median, correct.Median)
+	}
+
+	// Distributions that haven't been fixed are replayed into the other
+	small := NewAccumulatorWithOptions(opts...)
+	for _, v := range []int64{5, 2000, 15} {
+		small.Add(v)
+		sequential.Add(v)
+	}
+	if err := a.Merge(small); err != nil {
+		t.Fatal(err)
+	}
+	correct = sequential.GetStats()
+	if actual, correct := a.GetStats().OutlierAfter, correct.OutlierAfter; actual != correct {
+		t.Errorf("OutlierAfter: %d != %d", actual, correct)
+	}
+	if actual, correct := a.GetStats().FrequencyDistribution[0]-distribution[0], int64(2); actual != correct {
+		t.Errorf("First bucket growth: %d != %d", actual, correct)
+	}
+
+	c := NewAccumulatorWithOptions(WithWindow(10), WithBuckets(3))
+	for i := int64(0); i < 20; i++ {
+		c.Add(i)
+	}
+	count := a.intStats.Count
+	if err := a.Merge(c); err == nil {
+		t.Errorf("Merging different layouts should fail")
+	}
+	if actual, correct := a.intStats.Count, count; actual != correct {
+		t.Errorf("Failed merge changed count: %d != %d", actual, correct)
+	}
+
+	// An empty accumulator adopts the merged data
+	empty := NewAccumulatorWithOptions(opts...)
+	if err := empty.Merge(small); err != nil {
+		t.Fatal(err)
+	}
+	if is := empty.GetStats(); is.Count != 3 || is.Min != 5 || is.Max != 2000 {
+		t.Errorf("Merged into empty count %d min %d max %d", is.Count, is.Min, is.Max)
+	}
+}
+
+// shards returns n Accumulators with the same distribution layout and the
+// Accumulator of all their values added sequentially
+func shards(n, values int) ([]*Accumulator, *Accumulator) {
+	opts := []Option{WithWindow(100), WithBuckets(10), WithDistributionStart(0)}
+	r := rand.New(rand.NewSource(3))
+	sequential := NewAccumulatorWithOptions(opts...)
+	accumulators := make([]*Accumulator, n)
+	for i := range accumulators {
+		accumulators[i] = NewAccumulatorWithOptions(opts...)
+		accumulators[i].Add(999)
+		sequential.Add(999)
+		for j := 1; j < values; j++ {
+			v := r.Int63n(1000)
+			accumulators[i].Add(v)
+			sequential.Add(v)
+		}
+	}
+	return accumulators, sequential
+}
+
+func TestCombineInto(t *testing.T) {
+	accumulators, sequential := shards(64, 1000)
+	total := NewAccumulatorWithOptions(WithWindow(100), WithBuckets(10), WithDistributionStart(0))
+	for _, a := range accumulators {
+		if err := a.CombineInto(total); err != nil {
+			t.Fatal(err)
+		}
+	}
+	combined, correct := total.GetStats(), sequential.GetStats()
+	if combined.Count != correct.Count || combined.Min != correct.Min || combined.Max != correct.Max ||
+		combined.Sum.Cmp(correct.Sum) != 0 {
+		t.Errorf("Combined count %d min %d max %d sum %s != %d %d %d %s", combined.Count, combined.Min, combined.Max,
+			combined.Sum, correct.Count, correct.Min, correct.Max, correct.Sum)
+	}
+	if math.Abs(combined.Variance-correct.Variance) > 1e-6 {
+		t.Errorf("Variance %f != %f", combined.Variance, correct.Variance)
+	}
+	if !equalInt64s(combined.FrequencyDistribution, correct.FrequencyDistribution) {
+		t.Errorf("Distribution: %v != %v", combined.FrequencyDistribution, correct.FrequencyDistribution)
+	}
+}
+
+func BenchmarkCombineInto(b *testing.B) {
+	accumulators, _ := shards(64, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		total := NewAccumulatorWithOptions(WithWindow(100), WithBuckets(10), WithDistributionStart(0))
+		for _, a := range accumulators {
+			a.CombineInto(total)
+		}
+	}
+}
+
+func TestMergeStatsResamples(t *testing.T) {
+	var low, high []int64
+	for i := int64(0); i < 100; i++ {
+		low = append(low, i)
+	}
+	for i := int64(100); i < 300; i++ {
+		high = append(high, i)
+	}
+	a := loadStats(t, low...)
+	b := loadStats(t, high...)
+	if a.SameLayout(b) {
+		t.Fatalf("Layouts should differ")
+	}
+	m, err := MergeStats(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, correct := m.Count, int64(300); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	if actual, correct := m.FrequencyDistributionStartingValue, int64(0); actual != correct {
+		t.Errorf("Start: %d != %d", actual, correct)
+	}
+	if actual, correct := m.BucketSize, int64(75); actual != correct {
+		t.Errorf("Bucket size: %d != %d", actual, correct)
+	}
+	for i, count := range m.FrequencyDistribution {
+		if actual, correct := count, int64(75); actual != correct {
+			t.Errorf("Bucket %d: %d != %d", i, actual, correct)
+		}
+	}
+}
+
+func TestMergeStatsExactSamples(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithExactMode())
+	b := NewAccumulatorWithOptions(WithExactMode())
+	for v := int64(1); v <= 10; v++ {
+		a.Add(v)
+		b.Add(v * 100)
+	}
+	m, err := MergeStats(a.GetStats(), b.GetStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, correct := len(m.Sample), 20; actual != correct {
+		t.Fatalf("Sample: %d != %d", actual, correct)
+	}
+	if !sort.SliceIsSorted(m.Sample, func(i, j int) bool { return m.Sample[i] < m.Sample[j] }) {
+		t.Errorf("Sample isn't sorted: %v", m.Sample)
+	}
+	if actual, correct := m.Median, int64(10); actual != correct {
+		t.Errorf("Median: %d != %d", actual, correct)
+	}
+	if actual, correct := m.Percentile(0.9), int64(800); actual != correct {
+		t.Errorf("p90: %d != %d", actual, correct)
+	}
+
+	r := NewAccumulatorWithOptions(WithReservoir(5))
+	for v := int64(1); v <= 10; v++ {
+		r.Add(v)
+	}
+	if m, _ := MergeStats(a.GetStats(), r.GetStats()); m.Sample != nil {
+		t.Errorf("A partial sample shouldn't be merged: %v", m.Sample)
+	}
+}
+
+func TestMergeResampledFailure(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithExactMode(), WithWindow(100))
+	other := NewAccumulatorWithOptions(WithWindow(100))
+	for v := int64(0); v < 1000; v++ {
+		a.Add(v)
+		other.Add(v * 3)
+	}
+	before := a.Snapshot()
+	if before.SameLayout(other.Snapshot()) {
+		t.Fatal("Layouts should differ")
+	}
+	if err := a.mergeResampled(other); !errors.Is(err, ErrNotExact) {
+		t.Fatalf("mergeResampled: %v isn't %v", err, ErrNotExact)
+	}
+	after := a.Snapshot()
+	if !after.SameLayout(before) || !equalInt64s(after.FrequencyDistribution, before.FrequencyDistribution) {
+		t.Errorf("Layout changed by a failed merge: %d buckets of %d from %d",
+			len(after.FrequencyDistribution), after.BucketSize, after.FrequencyDistributionStartingValue)
+	}
+	if actual, correct := after.Count, before.Count; actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+}
@@ -0,0 +1,50 @@
+package cruncher
+
+// DefaultProminence is the prominence IsBimodal requires of each peak
+const DefaultProminence = 0.5
+
+// IsBimodal reports whether the frequency distribution has two or more
+// peaks separated by a significant valley, often a sign of mixed
+// populations, along with the indices of the peak buckets. See
+// PeaksWithProminence.
+func (is IntStats) IsBimodal() (bool, []int) {
+	peaks := is.PeaksWithProminence(DefaultProminence)
+	return len(peaks) >= 2, peaks
+}
+
+// PeaksWithProminence returns the indices of the buckets that are local
+// maxima of the frequency distribution. Adjacent peaks are only reported
+// separately when the valley between them dips below (1 - prominence) of
+// the smaller peak, otherwise only the higher peak, or the earlier one on
+// a tie, is reported. Outliers aren't considered.
+func (is IntStats) PeaksWithProminence(prominence float64) []int {
+	counts := is.FrequencyDistribution
+	var peaks []int
+	for i, c := range counts {
+		if c == 0 || (i > 0 && c < counts[i-1]) || (i < len(counts)-1 && c <= counts[i+1]) {
+			continue
+		}
+		if len(peaks) == 0 {
+			peaks = append(peaks, i)
+			continue
+		}
+		last := peaks[len(peaks)-1]
+		valley := c
+		for _, v := range counts[last:i] {
+			if v < valley {
+				valley = v
+			}
+		}
+		smaller := c
+		if counts[last] < smaller {
+			smaller = counts[last]
+		}
+		switch {
+		case float64(valley) <= (1-prominence)*float64(smaller):
+			peaks = append(peaks, i)
+		case c > counts[last]:
+			peaks[len(peaks)-1] = i
+		}
+	}
+	return peaks
+}
@@ -0,0 +1,32 @@
+package cruncher
+
+import "testing"
+
+func TestIsBimodal(t *testing.T) {
+	bimodal := IntStats{FrequencyDistribution: []int64{1, 5, 20, 6, 1, 0, 2, 8, 15, 9, 2}}
+	if ok, peaks := bimodal.IsBimodal(); !ok || !equalInts(peaks, []int{2, 8}) {
+		t.Errorf("Bimodal: %v %v", ok, peaks)
+	}
+	unimodal := IntStats{FrequencyDistribution: []int64{1, 5, 20, 18, 19, 10, 2, 0}}
+	if ok, peaks := unimodal.IsBimodal(); ok || !equalInts(peaks, []int{2}) {
+		t.Errorf("Unimodal: %v %v", ok, peaks)
+	}
+	if peaks := unimodal.PeaksWithProminence(0); !equalInts(peaks, []int{2, 4}) {
+		t.Errorf("Any dip separates peaks without prominence: %v", peaks)
+	}
+	if ok, peaks := (IntStats{}).IsBimodal(); ok || len(peaks) != 0 {
+		t.Errorf("Empty: %v %v", ok, peaks)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
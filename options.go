@@ -0,0 +1,392 @@
+package cruncher
+
+import (
+	"math"
+	"time"
+)
+
+// Option configures an Accumulator created by NewAccumulatorWithOptions
+type Option func(*Accumulator)
+
+// NewAccumulatorWithOptions allocates an accumulator configured by opts.
+// Options that aren't provided use DefaultApproximationWindow and
+// DefaultBuckets.
+func NewAccumulatorWithOptions(opts ...Option) *Accumulator {
+	a := new(Accumulator)
+	a.appoximationWindow = DefaultApproximationWindow
+	a.buckets = DefaultBuckets
+	for _, opt := range opts {
+		opt(a)
+	}
+	// Clamp to the smallest usable values rather than failing later
+	if a.appoximationWindow < 1 {
+		a.appoximationWindow = 1
+	}
+	if a.buckets < 1 {
+		a.buckets = 1
+	}
+	a.remedians = make([][]int64, 0, InitialRemedianSize)
+	if !a.withoutTermFrequency {
+		a.frequency = newSpaceSavingWithPolicy(a.termCapacity(), a.eviction)
+	} else {
+		a.dense = nil
+	}
+	if n := a.expectedCardinality; n > 0 {
+		if a.frequency != nil {
+			a.frequency.reserve(n)
+		}
+		if a.exact {
+			a.values = make([]int64, 0, n)
+		}
+	}
+	return a
+}
+
+// WithWindow sets the amount of data to sample before computing the min and
+// max for the frequency distribution. It's also the size of each block
+// used to approximate the median. Values less than 1 are treated as 1.
+func WithWindow(appoximationWindow int) Option {
+	return func(a *Accumulator) {
+		a.appoximationWindow = appoximationWindow
+	}
+}
+
+// WithBuckets sets the number of groups in the frequency distribution,
+// values less than 1 are treated as 1
+func WithBuckets(buckets int) Option {
+	return func(a *Accumulator) {
+		a.buckets = buckets
+	}
+}
+
+// WithName sets the name used to identify the data set in printed reports
+func WithName(name string) Option {
+	return func(a *Accumulator) {
+		a.Name = name
+	}
+}
+
+// WithDistributionStart pins the first bucket of the frequency distribution
+// to start. Without it the distribution starts at the smallest value in the
+// approximation window. Values below start are counted as OutlierBefore.
+func WithDistributionStart(start int64) Option {
+	return func(a *Accumulator) {
+		a.distributionStart = start
+		a.pinnedStart = true
+	}
+}
+
+// WithDistinctEstimate estimates the number of distinct values added using
+// a HyperLogLog sketch, the result is available in IntStats.DistinctEstimate.
+// The sketch uses 16KiB of memory and is accurate to within a few percent.
+func WithDistinctEstimate() Option {
+	return func(a *Accumulator) {
+		a.distinct = newHyperLogLog()
+	}
+}
+
+// WithoutTermFrequency disables tracking ValueFrequency, which avoids a map
+// lookup on every Add. GetTermFrequency returns an empty list.
+func WithoutTermFrequency() Option {
+	return func(a *Accumulator) {
+		a.withoutTermFrequency = true
+	}
+}
+
+// WithReservoir retains a uniform random sample of size values which
+// Percentile and Quantiles use in place of the frequency distribution.
+// The sample requires size int64s of memory, and percentiles are accurate
+// to roughly 1/sqrt(size) of the data regardless of how the data is
+// distributed. It's ignored when size isn't positive.
+func WithReservoir(size int) Option {
+	return func(a *Accumulator) {
+		if size > 0 {
+			a.reservoir = newReservoir(size)
+		}
+	}
+}
+
+// WithExactMode retains every value added so the Median, Percentile and
+// Quantiles are exact rather than approximated. Memory grows with the data,
+// 8 bytes per value plus a sorted copy each time the data is summarized,
+// so it's only suitable for data sets that fit in memory. The Remedian
+// isn't used in exact mode.
+func WithExactMode() Option {
+	return func(a *Accumulator) {
+		a.exact = true
+	}
+}
+
+// WithBucketRange tracks the smallest and largest value observed within
+// each bucket of the frequency distribution, see IntStats.BucketMin and
+// IntStats.BucketMax. It requires two additional int64s per bucket.
+func WithBucketRange() Option {
+	return func(a *Accumulator) {
+		a.bucketRange = true
+	}
+}
+
+// WithDecay maintains an exponentially weighted mean, IntStats.DecayedMean,
+// that favors recently added values. The weight of a value halves after
+// halfLife more values are added. IntStats.DecayedCount is the effective
+// number of values contributing to the mean.
+func WithDecay(halfLife float64) Option {
+	return func(a *Accumulator) {
+		if halfLife > 0 {
+			a.decay = newDecay(halfLife)
+		}
+	}
+}
+
+// WithSymLogBuckets groups values in the frequency distribution by their
+// signed order of magnitude, see SymLogScale. The number of buckets is
+// determined by the range of the values rather than WithBuckets.
+func WithSymLogBuckets() Option {
+	return func(a *Accumulator) {
+		a.scale = SymLogScale
+	}
+}
+
+// WithSkipValue treats v as a sentinel for missing data. Adding it only
+// increments Skipped, it's excluded from all the other statistics. The
+// option can be repeated to skip several values.
+func WithSkipValue(v int64) Option {
+	return func(a *Accumulator) {
+		if a.skip == nil {
+			a.skip = make(map[int64]bool)
+		}
+		a.skip[v] = true
+	}
+}
+
+// WithBucketWidth sets the number of values in each bucket of the frequency
+// distribution, the number of buckets is derived from the range of values
+// observed when the distribution is initialized, see BucketsForWidth. It
+// replaces WithBuckets and is ignored when width isn't positive or with
+// WithSymLogBuckets. When the range would need more than MaxBuckets
+// buckets, MaxBuckets wider buckets are used instead.
+func WithBucketWidth(width int64) Option {
+	return func(a *Accumulator) {
+		a.bucketWidth = width
+	}
+}
+
+// WithQuantize snaps values to the nearest multiple of step before they're
+// counted for the term frequency, reducing the number of distinct terms for
+// high-resolution data. Only the term frequency is quantized, every other
+// statistic, including the frequency distribution and median, uses the
+// values as added.
+func WithQuantize(step int64) Option {
+	return func(a *Accumulator) {
+		a.quantizeStep = step
+	}
+}
+
+// WithCoarsening widens the frequency distribution of an unbounded stream
+// whose range grows after the distribution is initialized. Once the
+// outliers exceed fraction of Count, pairs of adjacent buckets are merged,
+// halving the resolution, until the buckets cover Min to Max. The outlier
+// values are retained until then so they can be placed in the wider
+// buckets, which costs 8 bytes per outlier. The number of buckets is
+// unchanged but BucketSize doubles and the starting value may move down, so
+// the distribution is coarser than one laid out over the whole range and
+// Accumulators that coarsened differently can no longer be merged. It's
+// ignored WithSymLogBuckets.
+func WithCoarsening(fraction float64) Option {
+	return func(a *Accumulator) {
+		a.coarsening = fraction
+	}
+}
+
+// WithP2Quantiles maintains running estimates of the quantiles ps, each a
+// fraction from 0 to 1, in IntStats.P2Quantiles using the P² algorithm.
+// Each quantile uses constant space and time per value without retaining
+// the data, however the estimates can't be combined by Merge.
+func WithP2Quantiles(ps ...float64) Option {
+	return func(a *Accumulator) {
+		for _, p := range ps {
+			a.p2 = append(a.p2, newP2Quantile(p))
+		}
+	}
+}
+
+// WithDomain sets the range of valid values, from min to max inclusive, that
+// AddChecked accepts. Add remains lenient and accepts any value.
+func WithDomain(min, max int64) Option {
+	return func(a *Accumulator) {
+		a.domain = true
+		a.domainMin, a.domainMax = min, max
+	}
+}
+
+// Config returns the approximation window and the number of buckets the
+// Accumulator was created with. The effective number of buckets and their
+// BucketSize are available from GetStats once the distribution is laid out,
+// there may be fewer buckets when the range of values is small.
+func (a *Accumulator) Config() (window, buckets int) {
+	return a.appoximationWindow, a.buckets
+}
+
+// WithMedianEvenAverage computes the median of an even number of values as
+// the mean of the two middle values, rounded up, rather than the lower
+// middle value. It only applies when the median is exact, in exact mode or
+// while fewer values than the approximation window have been added.
+func WithMedianEvenAverage() Option {
+	return func(a *Accumulator) {
+		a.medianEvenAverage = true
+	}
+}
+
+// WithTDigest maintains a t-digest, a mergeable quantile sketch, that
+// Percentile and Quantiles use in place of the frequency distribution.
+// Memory is bounded by compression, roughly compression centroids of 16
+// bytes each plus a buffer of 5 * compression values; 100 is a typical
+// choice. The estimates are most accurate at the tails, such as p99, and
+// Merge combines the digests of each Accumulator.
+func WithTDigest(compression float64) Option {
+	return func(a *Accumulator) {
+		if compression > 0 {
+			a.digest = newTDigest(compression)
+		}
+	}
+}
+
+// WithExpectedCardinality pre-allocates the term frequency for n distinct
+// values, at most its capacity, see WithFrequencyCapacity, and in exact mode
+// room for n values, so they don't grow while values are added.
+func WithExpectedCardinality(n int) Option {
+	return func(a *Accumulator) {
+		a.expectedCardinality = n
+	}
+}
+
+// WithUnit sets the unit, such as "ms" or "bytes", printed after values
+func WithUnit(unit string) Option {
+	return func(a *Accumulator) {
+		a.Unit = unit
+	}
+}
+
+// WithInsertionOrderTies ranks equally frequent terms by when they were
+// first added, rather than by value, in GetTermFrequency
+func WithInsertionOrderTies() Option {
+	return func(a *Accumulator) {
+		a.insertionOrder = true
+	}
+}
+
+// WithDuration sets the period of time the data covers so the summary
+// includes the rate of values per second
+func WithDuration(d time.Duration) Option {
+	return func(a *Accumulator) {
+		a.Duration = d
+	}
+}
+
+// termCapacity is the number of values the term frequencies track
+func (a *Accumulator) termCapacity() int {
+	if a.frequencyCapacity > 0 {
+		return a.frequencyCapacity
+	}
+	return a.appoximationWindow
+}
+
+// WithFrequencyCapacity bounds the term frequency to n values rather than
+// the approximation window. Once it's full values are evicted according to
+// WithEvictionPolicy.
+func WithFrequencyCapacity(n int) Option {
+	return func(a *Accumulator) {
+		a.frequencyCapacity = n
+	}
+}
+
+// WithEvictionPolicy selects which value the term frequency stops tracking
+// when it's full, it defaults to EvictLeastFrequent
+func WithEvictionPolicy(policy EvictionPolicy) Option {
+	return func(a *Accumulator) {
+		a.eviction = policy
+	}
+}
+
+// RecommendWindow suggests an approximation window for WithWindow so the
+// Remedian's median of expectedN values is expected to be within
+// targetRelativeError of the true median's rank. For example 0.01 means the
+// estimate is typically between the 49th and 51st percentiles.
+//
+// The heuristic follows Rousseeuw and Bassett's analysis of the Remedian:
+// the median of a block of b values deviates from the middle rank with a
+// standard error of 1/(2*sqrt(b)), and each of the log_b(expectedN) levels
+// after the first inflates the variance by about π/2. It assumes the values
+// arrive in random order, sorted or trending input has a larger error. The
+// smallest window meeting the target is returned, expectedN when only a
+// single block, which gives the exact median, meets it.
+func RecommendWindow(expectedN int64, targetRelativeError float64) int {
+	if expectedN < 1 {
+		return 1
+	}
+	if targetRelativeError <= 0 {
+		return clampWindow(expectedN)
+	}
+	// The error decreases as the window grows so binary search for the
+	// smallest window meeting the target
+	low, high := int64(1), expectedN
+	for low < high {
+		mid := low + (high-low)/2
+		if remedianError(expectedN, mid) <= targetRelativeError {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return clampWindow(low)
+}
+
+// remedianError estimates the standard error, as a fraction of n, of the
+// rank of the Remedian's median of n values with blocks of size window
+func remedianError(n, window int64) float64 {
+	if window >= n {
+		return 0
+	}
+	levels := math.Ceil(math.Log(float64(n)) / math.Log(float64(window)))
+	return 0.5 / math.Sqrt(float64(window)) * math.Pow(math.Pi/2, (levels-1)/2)
+}
+
+// clampWindow converts window to an int, limited to the largest int
+func clampWindow(window int64) int {
+	if window > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(window)
+}
+
+// WithSampleRate only includes each value given to Add with probability
+// rate, from 0 to 1, which reduces the cost per value on very high
+// throughput streams. The stats describe the sample, EstimatedCount and
+// EstimatedSum scale them up to the whole stream. The sample's Mean,
+// Median and distribution shape are unbiased estimates when the values are
+// independent of their position in the stream, but their variance grows as
+// the rate shrinks, roughly as 1/sqrt(rate * n), and rare values may be
+// missed entirely. Rates of 1 or more include every value, and it's
+// ignored when rate isn't positive, so every value is included as well.
+func WithSampleRate(rate float64) Option {
+	return func(a *Accumulator) {
+		if rate > 0 && rate < 1 {
+			a.sampler = newSampler(rate)
+			a.intStats.SampleRate = rate
+		}
+	}
+}
+
+// WithDenseFrequency counts the term frequency of values between min and max
+// exactly in a slice rather than the bounded map, which is faster for small
+// dense domains such as HTTP status codes. Values outside the range fall back
+// to the map. The slice holds max-min+1 counts, so the range should be small,
+// only the first MaxDenseRange values of a wider range are counted in it.
+func WithDenseFrequency(min, max int64) Option {
+	return func(a *Accumulator) {
+		a.dense = nil
+		if max >= min {
+			a.dense = newDenseFrequency(min, max)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package cruncher
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestOptionDefaults(t *testing.T) {
+	a := NewAccumulatorWithOptions()
+	if actual, correct := a.appoximationWindow, DefaultApproximationWindow; actual != correct {
+		t.Errorf("Window: %d != %d", actual, correct)
+	}
+	if actual, correct := a.buckets, DefaultBuckets; actual != correct {
+		t.Errorf("Buckets: %d != %d", actual, correct)
+	}
+	if actual, correct := a.Name, ""; actual != correct {
+		t.Errorf("Name: %s != %s", actual, correct)
+	}
+	for i := int64(0); i < 2000; i++ {
+		a.Add(i)
+	}
+	if actual, correct := len(a.GetStats().FrequencyDistribution), DefaultBuckets; actual != correct {
+		t.Errorf("Distribution: %d != %d", actual, correct)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithWindow(10), WithBuckets(5), WithName("shard"))
+	if actual, correct := a.appoximationWindow, 10; actual != correct {
+		t.Errorf("Window: %d != %d", actual, correct)
+	}
+	for i := int64(0); i < 100; i++ {
+		a.Add(i)
+	}
+	is := a.GetStats()
+	if actual, correct := len(is.FrequencyDistribution), 5; actual != correct {
+		t.Errorf("Distribution: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Name, "shard"; actual != correct {
+		t.Errorf("Name: %s != %s", actual, correct)
+	}
+
+	b := NewAccumulatorWithOptions(WithBuckets(3))
+	if actual, correct := b.appoximationWindow, DefaultApproximationWindow; actual != correct {
+		t.Errorf("Window: %d != %d", actual, correct)
+	}
+	if actual, correct := b.buckets, 3; actual != correct {
+		t.Errorf("Buckets: %d != %d", actual, correct)
+	}
+}
+
+func TestDistributionStart(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithWindow(100), WithBuckets(10), WithDistributionStart(0))
+	a.Add(-5)
+	for i := int64(0); i < 20; i++ {
+		a.Add(50 + i)
+	}
+	if actual, correct := a.GetStats().OutlierBefore, int64(1); actual != correct {
+		t.Errorf("OutlierBefore: %d != %d", actual, correct)
+	}
+	a.Add(-1)
+	a.Add(-2)
+	is := a.GetStats()
+	if actual, correct := is.FrequencyDistributionStartingValue, int64(0); actual != correct {
+		t.Errorf("Start: %d != %d", actual, correct)
+	}
+	if actual, correct := is.OutlierBefore, int64(3); actual != correct {
+		t.Errorf("OutlierBefore: %d != %d", actual, correct)
+	}
+	var total int64
+	for _, v := range is.FrequencyDistribution {
+		total += v
+	}
+	if actual, correct := total+is.OutlierBefore+is.OutlierAfter, is.Count; actual != correct {
+		t.Errorf("Distribution total: %d != %d", actual, correct)
+	}
+}
+
+func TestSkipValue(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithSkipValue(-1), WithSkipValue(math.MinInt64))
+	for i := int64(1); i <= 100; i++ {
+		a.Add(i)
+		if i%10 == 0 {
+			a.Add(-1)
+		}
+		if i%25 == 0 {
+			a.Add(math.MinInt64)
+		}
+	}
+	is := a.GetStats()
+	if actual, correct := is.Count, int64(100); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Skipped, int64(14); actual != correct {
+		t.Errorf("Skipped: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Min, int64(1); actual != correct {
+		t.Errorf("Min: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Mean, 50.5; actual != correct {
+		t.Errorf("Mean: %f != %f", actual, correct)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	a := NewAccumulator(250, 7)
+	if window, buckets := a.Config(); window != 250 || buckets != 7 {
+		t.Errorf("Config: %d %d != 250 7", window, buckets)
+	}
+	if window, buckets := NewAccumulatorWithOptions().Config(); window != DefaultApproximationWindow || buckets != DefaultBuckets {
+		t.Errorf("Default config: %d %d", window, buckets)
+	}
+	for i := int64(0); i < 70; i++ {
+		a.Add(i)
+	}
+	if actual, correct := a.GetStats().BucketSize, int64(10); actual != correct {
+		t.Errorf("BucketSize: %d != %d", actual, correct)
+	}
+}
+
+func TestMedianEvenAverage(t *testing.T) {
+	for _, c := range []struct {
+		opts    []Option
+		correct int64
+	}{
+		{nil, 2},
+		{[]Option{WithMedianEvenAverage()}, 3},
+		{[]Option{WithMedianEvenAverage(), WithExactMode()}, 3},
+		{[]Option{WithExactMode()}, 2},
+	} {
+		a := NewAccumulatorWithOptions(c.opts...)
+		for _, v := range []int64{4, 1, 3, 2} {
+			a.Add(v)
+		}
+		if actual := a.GetStats().Median; actual != c.correct {
+			t.Errorf("Median: %d != %d", actual, c.correct)
+		}
+	}
+	if actual, correct := middle([]int64{math.MinInt64, math.MaxInt64}, true), int64(0); actual != correct {
+		t.Errorf("Wide median: %d != %d", actual, correct)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	for _, c := range []struct{ window, buckets int }{{0, 0}, {-5, 10}, {10, -1}, {0, 5}} {
+		a := NewAccumulator(c.window, c.buckets)
+		window, buckets := a.Config()
+		if window < 1 || buckets < 1 || (c.window >= 1 && window != c.window) || (c.buckets >= 1 && buckets != c.buckets) {
+			t.Errorf("NewAccumulator(%d, %d) config: %d %d", c.window, c.buckets, window, buckets)
+		}
+		for i := int64(0); i < 100; i++ {
+			a.Add(i)
+		}
+		var buf bytes.Buffer
+		a.Print(&buf)
+		is := a.GetStats()
+		if is.Count != 100 || is.Min != 0 || is.Max != 99 {
+			t.Errorf("NewAccumulator(%d, %d) stats: %d %d %d", c.window, c.buckets, is.Count, is.Min, is.Max)
+		}
+		if err := a.Validate(); err != nil {
+			t.Errorf("NewAccumulator(%d, %d): %v", c.window, c.buckets, err)
+		}
+	}
+}
+
+func TestRecommendWindow(t *testing.T) {
+	previous := 0
+	for _, target := range []float64{0.1, 0.05, 0.01, 0.005, 0.001} {
+		window := RecommendWindow(1000000, target)
+		if window <= previous {
+			t.Errorf("Window for %f should exceed %d: %d", target, previous, window)
+		}
+		if actual := remedianError(1000000, int64(window)); actual > target {
+			t.Errorf("Window %d error %f exceeds %f", window, actual, target)
+		}
+		previous = window
+	}
+	if actual, correct := RecommendWindow(100, 0.0001), 100; actual != correct {
+		t.Errorf("Small data sets should use a single block: %d != %d", actual, correct)
+	}
+	if actual, correct := RecommendWindow(0, 0.01), 1; actual != correct {
+		t.Errorf("No data: %d != %d", actual, correct)
+	}
+}
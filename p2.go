@@ -0,0 +1,112 @@
+package cruncher
+
+import "sort"
+
+// Quantile is a running estimate of the value below which a fraction P of
+// the values fall
+type Quantile struct {
+	P     float64
+	Value float64
+}
+
+// p2Quantile estimates a single quantile in constant space using the P²
+// algorithm of Jain and Chlamtac. Five markers track the minimum, the
+// maximum, the quantile and the quantiles half way to the extremes; their
+// heights are adjusted with piecewise parabolic interpolation as values
+// are added.
+type p2Quantile struct {
+	p       float64
+	count   int
+	heights [5]float64
+	// positions are the actual marker positions, desired the ideal ones
+	// and increments how far the ideal positions move with each value
+	positions  [5]float64
+	desired    [5]float64
+	increments [5]float64
+}
+
+func newP2Quantile(p float64) *p2Quantile {
+	return &p2Quantile{
+		p:          p,
+		positions:  [5]float64{0, 1, 2, 3, 4},
+		desired:    [5]float64{0, 2 * p, 4 * p, 2 + 2*p, 4},
+		increments: [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}
+}
+
+func (q *p2Quantile) add(value int64) {
+	x := float64(value)
+	if q.count < len(q.heights) {
+		q.heights[q.count] = x
+		q.count++
+		if q.count == len(q.heights) {
+			sort.Float64s(q.heights[:])
+		}
+		return
+	}
+	q.count++
+	// Find the cell containing x, extending the extremes if needed
+	var k int
+	switch {
+	case x < q.heights[0]:
+		q.heights[0] = x
+	case x >= q.heights[4]:
+		q.heights[4] = x
+		k = 3
+	default:
+		for k = 0; x >= q.heights[k+1]; k++ {
+		}
+	}
+	for i := k + 1; i < 5; i++ {
+		q.positions[i]++
+	}
+	for i := range q.desired {
+		q.desired[i] += q.increments[i]
+	}
+	// Move the middle markers that are at least a position from ideal
+	for i := 1; i < 4; i++ {
+		d := q.desired[i] - q.positions[i]
+		if (d >= 1 && q.positions[i+1]-q.positions[i] > 1) || (d <= -1 && q.positions[i-1]-q.positions[i] < -1) {
+			s := 1.0
+			if d < 0 {
+				s = -1
+			}
+			h := q.parabolic(i, s)
+			if h <= q.heights[i-1] || h >= q.heights[i+1] {
+				h = q.linear(i, s)
+			}
+			q.heights[i] = h
+			q.positions[i] += s
+		}
+	}
+}
+
+func (q *p2Quantile) parabolic(i int, s float64) float64 {
+	n, h := q.positions, q.heights
+	return h[i] + s/(n[i+1]-n[i-1])*
+		((n[i]-n[i-1]+s)*(h[i+1]-h[i])/(n[i+1]-n[i])+
+			(n[i+1]-n[i]-s)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+}
+
+func (q *p2Quantile) linear(i int, s float64) float64 {
+	j := i + int(s)
+	return q.heights[i] + s*(q.heights[j]-q.heights[i])/(q.positions[j]-q.positions[i])
+}
+
+// estimate returns the current estimate, until five values are added it's
+// the nearest rank of those values
+func (q *p2Quantile) estimate() float64 {
+	if q.count >= len(q.heights) {
+		return q.heights[2]
+	}
+	if q.count == 0 {
+		return 0
+	}
+	values := append([]float64(nil), q.heights[:q.count]...)
+	sort.Float64s(values)
+	i := int(q.p * float64(q.count))
+	if i >= q.count {
+		i = q.count - 1
+	}
+	return values[i]
+}
@@ -0,0 +1,43 @@
+package cruncher
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestP2Quantiles(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithP2Quantiles(0.5, 0.9, 0.99))
+	r := rand.New(rand.NewSource(7))
+	values := make([]int64, 0, 200000)
+	for i := 0; i < cap(values); i++ {
+		v := int64(r.ExpFloat64() * 1000)
+		values = append(values, v)
+		a.Add(v)
+	}
+	sort.Sort(int64arr(values))
+	is := a.GetStats()
+	if actual, correct := len(is.P2Quantiles), 3; actual != correct {
+		t.Fatalf("P2Quantiles: %d != %d", actual, correct)
+	}
+	for _, q := range is.P2Quantiles {
+		exact := float64(values[int(q.P*float64(len(values)))])
+		if math.Abs(q.Value-exact) > 0.02*exact {
+			t.Errorf("P%g: %f isn't within 2%% of %f", 100*q.P, q.Value, exact)
+		}
+	}
+}
+
+func TestP2QuantilesFewValues(t *testing.T) {
+	q := newP2Quantile(0.5)
+	if actual, correct := q.estimate(), 0.0; actual != correct {
+		t.Errorf("Empty: %f != %f", actual, correct)
+	}
+	for _, v := range []int64{9, 1, 5} {
+		q.add(v)
+	}
+	if actual, correct := q.estimate(), 5.0; actual != correct {
+		t.Errorf("Median: %f != %f", actual, correct)
+	}
+}
@@ -0,0 +1,220 @@
+package cruncher
+
+import (
+	"math"
+	"sort"
+)
+
+// Percentile estimates the value below which the fraction p (0.0 - 1.0) of
+// the data falls using the most accurate source available: the Sample when
+// one was retained, then the t-digest Centroids, otherwise the frequency
+// distribution.
+func (is IntStats) Percentile(p float64) int64 {
+	return is.Quantiles(p)[0]
+}
+
+// Quantiles estimates several percentiles at once, see Percentile.
+// The results are in the same order as ps and are computed in a single
+// pass over the data.
+func (is IntStats) Quantiles(ps ...float64) []int64 {
+	if len(is.Sample) > 0 {
+		return is.quantilesFromSample(ps)
+	}
+	if len(is.Centroids) > 0 {
+		return is.quantilesFromCentroids(ps)
+	}
+	return is.quantilesFromDistribution(ps)
+}
+
+// Percentile estimates the value below which the fraction p (0.0 - 1.0) of
+// the values added so far falls without summarizing the data, so it can be
+// polled while values are still being added. It uses the exact values,
+// the reservoir sample or the t-digest, whichever is configured, or the P²
+// estimate when p is one of the WithP2Quantiles. false is returned when
+// there's no such backend or no values have been added.
+func (a *Accumulator) Percentile(p float64) (int64, bool) {
+	if a.intStats.Count == 0 {
+		return 0, false
+	}
+	is := IntStats{Min: a.intStats.Min, Max: a.intStats.Max}
+	switch {
+	case a.exact:
+		is.Sample = append([]int64(nil), a.values...)
+		sort.Sort(int64arr(is.Sample))
+	case a.reservoir != nil:
+		is.Sample = a.reservoir.sorted()
+	case a.digest != nil:
+		is.Centroids = a.digest.compressed()
+	default:
+		for _, q := range a.p2 {
+			if q.p == p {
+				return clampInt64(q.estimate()), true
+			}
+		}
+		return 0, false
+	}
+	return is.Percentile(p), true
+}
+
+// ValueAtRank estimates the rank-th smallest value, from 1 for Min to Count
+// for Max, such as the 1,000,000th observation when sorted. It's estimated
+// with Percentile. ok is false when rank is outside of 1 to Count.
+func (is IntStats) ValueAtRank(rank int64) (value int64, ok bool) {
+	if rank < 1 || rank > is.Count {
+		return 0, false
+	}
+	switch rank {
+	case 1:
+		return is.Min, true
+	case is.Count:
+		return is.Max, true
+	}
+	return is.Percentile(float64(rank) / float64(is.Count)), true
+}
+
+// PercentileFromDistribution estimates the value below which the fraction p
+// (0.0 - 1.0) of the data falls. The estimate is interpolated from the
+// frequency distribution assuming values are evenly spread within a bucket.
+func (is IntStats) PercentileFromDistribution(p float64) int64 {
+	return is.quantilesFromDistribution([]float64{p})[0]
+}
+
+// OutlierBounds returns Tukey's fences, 1.5 times the interquartile range
+// below the first quartile and above the third. Values outside of them are
+// statistical outliers. The quartiles are estimated with Quantiles.
+func (is IntStats) OutlierBounds() (low, high int64) {
+	q := is.Quantiles(0.25, 0.75)
+	iqr := float64(q[1]) - float64(q[0])
+	return clampInt64(float64(q[0]) - 1.5*iqr), clampInt64(float64(q[1]) + 1.5*iqr)
+}
+
+// BoxPlotData is the five-number summary and whiskers needed to draw a box
+// plot
+type BoxPlotData struct {
+	Min    int64
+	Q1     int64
+	Median int64
+	Q3     int64
+	Max    int64
+	// LowerWhisker and UpperWhisker are Tukey's fences, see OutlierBounds,
+	// limited to the range of the data. Values beyond them are outliers.
+	LowerWhisker int64
+	UpperWhisker int64
+}
+
+// BoxPlot returns the quartiles, estimated with Quantiles, along with the
+// range of the data and the IQR based whiskers
+func (is IntStats) BoxPlot() BoxPlotData {
+	q := is.Quantiles(0.25, 0.5, 0.75)
+	box := BoxPlotData{Min: is.Min, Q1: q[0], Median: q[1], Q3: q[2], Max: is.Max}
+	box.LowerWhisker, box.UpperWhisker = is.OutlierBounds()
+	if box.LowerWhisker < is.Min {
+		box.LowerWhisker = is.Min
+	}
+	if box.UpperWhisker > is.Max {
+		box.UpperWhisker = is.Max
+	}
+	return box
+}
+
+// clampInt64 converts f to the nearest int64
+func clampInt64(f float64) int64 {
+	switch {
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(math.Round(f))
+}
+
+// ProportionWithin estimates the fraction of the values within k standard
+// deviations of the Mean from the frequency distribution. For normally
+// distributed data it's about 0.68, 0.95 and 0.997 for k of 1, 2 and 3.
+func (is IntStats) ProportionWithin(k float64) float64 {
+	if is.Count == 0 {
+		return 0
+	}
+	low := clampInt64(math.Ceil(is.Mean - k*is.StdDev))
+	high := clampInt64(math.Floor(is.Mean + k*is.StdDev))
+	if high < low {
+		return 0
+	}
+	below := 0.0
+	if low > math.MinInt64 {
+		below = is.PercentileRank(low - 1)
+	}
+	return is.PercentileRank(high) - below
+}
+
+// DistributionMedian estimates the median by interpolating within the
+// bucket containing the middle of the frequency distribution. It's
+// independent of the Remedian so it can be used to cross-check Median.
+func (is IntStats) DistributionMedian() int64 {
+	return is.PercentileFromDistribution(0.5)
+}
+
+// quantilesFromDistribution computes the percentiles ps from a single
+// cumulative scan of the frequency distribution
+func (is IntStats) quantilesFromDistribution(ps []float64) []int64 {
+	results := make([]int64, len(ps))
+	if is.Count == 0 {
+		return results
+	}
+	order := make([]int, len(ps))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return ps[order[i]] < ps[order[j]] })
+
+	buckets := is.Buckets()
+	b := 0
+	var cumulative float64
+	for _, i := range order {
+		p := ps[i]
+		if p <= 0 {
+			results[i] = is.Min
+			continue
+		}
+		target := p * float64(is.Count)
+		for b < len(buckets) && (buckets[b].Count == 0 || cumulative+float64(buckets[b].Count) < target) {
+			cumulative += float64(buckets[b].Count)
+			b++
+		}
+		if p >= 1 || b == len(buckets) {
+			results[i] = is.Max
+			continue
+		}
+		width := float64(span(buckets[b].LowerBound, buckets[b].UpperBound)) + 1
+		value := addClamped(buckets[b].LowerBound, uint64((target-cumulative)/float64(buckets[b].Count)*width))
+		if value > buckets[b].UpperBound {
+			value = buckets[b].UpperBound
+		}
+		results[i] = value
+	}
+	return results
+}
+
+// PercentileRank estimates the fraction (0.0 - 1.0) of the data that is less
+// than or equal to value. It's the inverse of PercentileFromDistribution
+// and is interpolated within the bucket containing value.
+func (is IntStats) PercentileRank(value int64) float64 {
+	if is.Count == 0 || value < is.Min {
+		return 0
+	}
+	if value >= is.Max {
+		return 1
+	}
+	var cumulative float64
+	for _, b := range is.Buckets() {
+		if value > b.UpperBound {
+			cumulative += float64(b.Count)
+			continue
+		}
+		if value >= b.LowerBound {
+			cumulative += float64(b.Count) * (float64(span(b.LowerBound, value)) + 1) / (float64(span(b.LowerBound, b.UpperBound)) + 1)
+		}
+		break
+	}
+	return cumulative / float64(is.Count)
+}
@@ -0,0 +1,164 @@
+package cruncher
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func uniformStats(n int, limit int64) IntStats {
+	a := NewAccumulator(1000, 20)
+	for i := 0; i < n; i++ {
+		a.Add(rand.Int63n(limit))
+	}
+	return a.GetStats()
+}
+
+func TestPercentileRank(t *testing.T) {
+	is := uniformStats(100000, 10000)
+	if actual, correct := is.PercentileRank(is.Min-1), 0.0; actual != correct {
+		t.Errorf("Rank below range: %f != %f", actual, correct)
+	}
+	if actual, correct := is.PercentileRank(is.Max), 1.0; actual != correct {
+		t.Errorf("Rank above range: %f != %f", actual, correct)
+	}
+	if actual, correct := is.PercentileFromDistribution(0.5), int64(5000); math.Abs(float64(actual-correct)) > 200 {
+		t.Errorf("Median from distribution: %d != %d", actual, correct)
+	}
+	for p := 0.05; p < 1; p += 0.05 {
+		value := is.PercentileFromDistribution(p)
+		if actual := is.PercentileRank(value); math.Abs(actual-p) > 0.01 {
+			t.Errorf("PercentileRank(PercentileFromDistribution(%f)) = %f (value %d)", p, actual, value)
+		}
+	}
+}
+
+func TestPercentileEmpty(t *testing.T) {
+	var is IntStats
+	if actual, correct := is.PercentileFromDistribution(0.5), int64(0); actual != correct {
+		t.Errorf("Empty percentile: %d != %d", actual, correct)
+	}
+	if actual, correct := is.PercentileRank(10), 0.0; actual != correct {
+		t.Errorf("Empty rank: %f != %f", actual, correct)
+	}
+}
+
+func TestQuantiles(t *testing.T) {
+	is := uniformStats(100000, 10000)
+	ps := []float64{0.99, 0.5, 0.9, 0, 1}
+	qs := is.Quantiles(ps...)
+	if actual, correct := len(qs), len(ps); actual != correct {
+		t.Fatalf("Quantiles: %d != %d", actual, correct)
+	}
+	for i, p := range ps {
+		if actual, correct := qs[i], is.Percentile(p); actual != correct {
+			t.Errorf("Quantile %f: %d != %d", p, actual, correct)
+		}
+	}
+	if qs[0] < qs[2] || qs[2] < qs[1] {
+		t.Errorf("Quantiles are out of order: %v", qs)
+	}
+	if actual, correct := qs[3], is.Min; actual != correct {
+		t.Errorf("Quantile 0: %d != %d", actual, correct)
+	}
+	if actual, correct := qs[4], is.Max; actual != correct {
+		t.Errorf("Quantile 1: %d != %d", actual, correct)
+	}
+}
+
+func TestDistributionMedian(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithWindow(1000), WithBuckets(20), WithExactMode())
+	r := rand.New(rand.NewSource(5))
+	for i := 0; i < 50000; i++ {
+		a.Add(r.Int63n(10000))
+	}
+	is := a.GetStats()
+	if diff := is.DistributionMedian() - is.Median; diff < -is.BucketSize || diff > is.BucketSize {
+		t.Errorf("DistributionMedian %d isn't within %d of %d", is.DistributionMedian(), is.BucketSize, is.Median)
+	}
+}
+
+func TestOutlierBounds(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithExactMode(), WithBuckets(5))
+	// Adding the extreme values first spreads the buckets over them
+	a.Add(10000)
+	a.Add(-5000)
+	r := rand.New(rand.NewSource(9))
+	for i := 0; i < 1000; i++ {
+		a.Add(100 + r.Int63n(101))
+	}
+	is := a.GetStats()
+	low, high := is.OutlierBounds()
+	if low <= -5000 || high >= 10000 {
+		t.Errorf("Bounds %d - %d should exclude the extreme values", low, high)
+	}
+	if low > 100 || high < 200 {
+		t.Errorf("Bounds %d - %d should include the typical values", low, high)
+	}
+	a.MarkIQROutliers = true
+	var buf bytes.Buffer
+	a.GetStats().PrintFrequencyDistribution(&buf)
+	report := buf.String()
+	if !strings.Contains(report, "   -5000 -    -2000 :       1 (0.10%) !!") {
+		t.Errorf("The extreme bucket should be marked:\n%s", report)
+	}
+	if strings.Contains(report, "1001 :    1000 (99.80%) !!") {
+		t.Errorf("The typical bucket shouldn't be marked:\n%s", report)
+	}
+}
+
+func TestProportionWithin(t *testing.T) {
+	a := NewAccumulator(10000, 100)
+	for i := 0; i < 100000; i++ {
+		a.Add(gausian(10000, 1000))
+	}
+	is := a.GetStats()
+	for _, c := range []struct{ k, correct float64 }{{1, 0.6827}, {2, 0.9545}, {3, 0.9973}} {
+		if actual := is.ProportionWithin(c.k); math.Abs(actual-c.correct) > 0.01 {
+			t.Errorf("ProportionWithin(%g): %f != %f", c.k, actual, c.correct)
+		}
+	}
+	if actual, correct := (IntStats{}).ProportionWithin(1), 0.0; actual != correct {
+		t.Errorf("Empty: %f != %f", actual, correct)
+	}
+}
+
+func TestBoxPlot(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithReservoir(200))
+	for i := int64(1); i <= 100; i++ {
+		a.Add(i)
+	}
+	a.Add(1000)
+	box := a.GetStats().BoxPlot()
+	if actual, correct := box, (BoxPlotData{Min: 1, Q1: 26, Median: 51, Q3: 76, Max: 1000,
+		LowerWhisker: 1, UpperWhisker: 151}); actual != correct {
+		t.Errorf("BoxPlot: %+v != %+v", actual, correct)
+	}
+	if actual, correct := (IntStats{}).BoxPlot(), (BoxPlotData{}); actual != correct {
+		t.Errorf("Empty BoxPlot: %+v != %+v", actual, correct)
+	}
+}
+
+func TestValueAtRank(t *testing.T) {
+	is := uniformStats(100000, 10000)
+	median, ok := is.ValueAtRank(is.Count / 2)
+	if !ok {
+		t.Fatalf("ValueAtRank(%d) failed", is.Count/2)
+	}
+	if actual, correct := median, is.Median; math.Abs(float64(actual-correct)) > 200 {
+		t.Errorf("ValueAtRank(Count/2): %d != %d", actual, correct)
+	}
+	if actual, ok := is.ValueAtRank(1); !ok || actual != is.Min {
+		t.Errorf("ValueAtRank(1): %d != %d", actual, is.Min)
+	}
+	if actual, ok := is.ValueAtRank(is.Count); !ok || actual != is.Max {
+		t.Errorf("ValueAtRank(Count): %d != %d", actual, is.Max)
+	}
+	for _, rank := range []int64{0, is.Count + 1} {
+		if _, ok := is.ValueAtRank(rank); ok {
+			t.Errorf("ValueAtRank(%d) should be out of range", rank)
+		}
+	}
+}
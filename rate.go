@@ -0,0 +1,20 @@
+package cruncher
+
+// Rate returns the number of values added per second of the Duration the
+// data covers, it's 0 without a Duration
+func (is IntStats) Rate() float64 {
+	if is.Duration <= 0 {
+		return 0
+	}
+	return float64(is.Count) / is.Duration.Seconds()
+}
+
+// SumRate returns the total of the values added per second of the Duration
+// the data covers, such as the throughput when the values are sizes in
+// bytes. It's 0 without a Duration.
+func (is IntStats) SumRate() float64 {
+	if is.Duration <= 0 || is.Count == 0 {
+		return 0
+	}
+	return is.Mean * float64(is.Count) / is.Duration.Seconds()
+}
@@ -0,0 +1,35 @@
+package cruncher
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRate(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithDuration(10 * time.Second))
+	for i := int64(0); i < 1000; i++ {
+		a.Add(i % 10)
+	}
+	is := a.GetStats()
+	if actual, correct := is.Rate(), 100.0; actual != correct {
+		t.Errorf("Rate: %f != %f", actual, correct)
+	}
+	if actual, correct := is.SumRate(), 450.0; actual != correct {
+		t.Errorf("SumRate: %f != %f", actual, correct)
+	}
+	var buf bytes.Buffer
+	a.Print(&buf)
+	if !strings.Contains(buf.String(), "Rate              100.000/s\n") {
+		t.Errorf("Report is missing the rate:\n%s", buf.String())
+	}
+	buf.Reset()
+	NewAccumulator(10, 10).Print(&buf)
+	if strings.Contains(buf.String(), "Rate") {
+		t.Errorf("Rate shouldn't be printed without a duration:\n%s", buf.String())
+	}
+	if actual, correct := (IntStats{Count: 5}).Rate(), 0.0; actual != correct {
+		t.Errorf("Rate without duration: %f != %f", actual, correct)
+	}
+}
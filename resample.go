@@ -0,0 +1,95 @@
+package cruncher
+
+import "math"
+
+// Resample returns a copy of the stats with adjacent buckets merged into a
+// coarser frequency distribution of at most targetBuckets buckets, such as
+// for plotting a very wide distribution. The counts and outliers are
+// preserved exactly. The stats are returned unchanged when they already
+// have no more than targetBuckets buckets or use the SymLogScale.
+func (is IntStats) Resample(targetBuckets int) IntStats {
+	n := len(is.FrequencyDistribution)
+	if is.Scale != LinearScale || targetBuckets < 1 || n <= targetBuckets {
+		return is.clone()
+	}
+	k := (n + targetBuckets - 1) / targetBuckets
+	return is.resampleTo(is.FrequencyDistributionStartingValue, is.BucketSize*int64(k), (n+k-1)/k)
+}
+
+// resampleTo returns a copy of the stats with the frequency distribution
+// moved to n linear buckets of size starting at start. Buckets that only
+// partially overlap a new bucket are split in proportion to the overlap,
+// assuming values are evenly spread within a bucket, and values outside the
+// new buckets become outliers. The total count is preserved. BucketMin and
+// BucketMax are only kept when no bucket is split.
+func (is IntStats) resampleTo(start, size int64, n int) IntStats {
+	r := is.clone()
+	r.Scale = LinearScale
+	r.FrequencyDistributionStartingValue = start
+	r.BucketSize = size
+	r.FrequencyDistribution = make([]int64, n)
+	if is.BucketMin != nil {
+		r.BucketMin, r.BucketMax = make([]int64, n), make([]int64, n)
+	}
+	// add places count values in bucket j, -1 and n are the outliers
+	add := func(j int, count int64) {
+		switch {
+		case j < 0:
+			r.OutlierBefore += count
+		case j >= n:
+			r.OutlierAfter += count
+		default:
+			r.FrequencyDistribution[j] += count
+		}
+	}
+	clamp := func(j int) int {
+		if j < -1 {
+			return -1
+		}
+		if j > n {
+			return n
+		}
+		return j
+	}
+	split := false
+	for i, count := range is.FrequencyDistribution {
+		if count == 0 {
+			continue
+		}
+		lower, upper := is.bucketBounds(i)
+		first, last := clamp(r.bucketOffset(lower)), clamp(r.bucketOffset(upper))
+		if first == last {
+			if r.BucketMin != nil && first >= 0 && first < n {
+				if r.FrequencyDistribution[first] == 0 || is.BucketMin[i] < r.BucketMin[first] {
+					r.BucketMin[first] = is.BucketMin[i]
+				}
+				if r.FrequencyDistribution[first] == 0 || is.BucketMax[i] > r.BucketMax[first] {
+					r.BucketMax[first] = is.BucketMax[i]
+				}
+			}
+			add(first, count)
+			continue
+		}
+		split = true
+		// Round the cumulative share so the portions sum to count
+		width := float64(upper) - float64(lower) + 1
+		var assigned int64
+		for j := first; j <= last; j++ {
+			end := upper
+			switch {
+			case j == last:
+			case j < 0:
+				end = start - 1
+			default:
+				_, end = r.bucketBounds(j)
+			}
+			cumulative := int64(math.Round(float64(count) * (float64(end) - float64(lower) + 1) / width))
+			add(j, cumulative-assigned)
+			assigned = cumulative
+		}
+	}
+	if split {
+		r.BucketMin, r.BucketMax = nil, nil
+	}
+	return r
+}
@@ -0,0 +1,70 @@
+package cruncher
+
+import "testing"
+
+func TestResample(t *testing.T) {
+	is := IntStats{
+		Min:                                -50,
+		Max:                                2000,
+		BucketSize:                         7,
+		FrequencyDistributionStartingValue: 3,
+		FrequencyDistribution:              make([]int64, 100),
+		OutlierBefore:                      4,
+		OutlierAfter:                       6,
+	}
+	is.Count = is.OutlierBefore + is.OutlierAfter
+	for i := range is.FrequencyDistribution {
+		is.FrequencyDistribution[i] = int64(i % 13)
+		is.Count += int64(i % 13)
+	}
+	r := is.Resample(10)
+	if actual, correct := len(r.FrequencyDistribution), 10; actual != correct {
+		t.Fatalf("Buckets: %d != %d", actual, correct)
+	}
+	if actual, correct := r.BucketSize, int64(70); actual != correct {
+		t.Errorf("BucketSize: %d != %d", actual, correct)
+	}
+	if r.OutlierBefore != is.OutlierBefore || r.OutlierAfter != is.OutlierAfter {
+		t.Errorf("Outliers: %d %d != %d %d", r.OutlierBefore, r.OutlierAfter, is.OutlierBefore, is.OutlierAfter)
+	}
+	var total int64
+	buckets := r.Buckets()
+	for i, b := range buckets {
+		total += b.Count
+		if i > 0 && b.LowerBound != buckets[i-1].UpperBound+1 {
+			t.Errorf("Bucket %d isn't contiguous %d != %d", i, b.LowerBound, buckets[i-1].UpperBound+1)
+		}
+	}
+	if actual, correct := total, is.Count; actual != correct {
+		t.Errorf("Total: %d != %d", actual, correct)
+	}
+	for j := range r.FrequencyDistribution {
+		var correct int64
+		for _, c := range is.FrequencyDistribution[10*j : 10*j+10] {
+			correct += c
+		}
+		if actual := r.FrequencyDistribution[j]; actual != correct {
+			t.Errorf("Bucket %d: %d != %d", j, actual, correct)
+		}
+	}
+	if actual, correct := len(is.Resample(30).FrequencyDistribution), 25; actual != correct {
+		t.Errorf("Uneven resample: %d != %d", actual, correct)
+	}
+	if actual, correct := len(is.Resample(500).FrequencyDistribution), 100; actual != correct {
+		t.Errorf("Finer resample: %d != %d", actual, correct)
+	}
+}
+
+func TestResampleTo(t *testing.T) {
+	is := IntStats{
+		Count:                              30,
+		BucketSize:                         10,
+		FrequencyDistributionStartingValue: 0,
+		FrequencyDistribution:              []int64{10, 10, 10},
+	}
+	// Buckets of 15 starting at 5 split each bucket of 10
+	r := is.resampleTo(5, 15, 2)
+	if !equalInt64s(r.FrequencyDistribution, []int64{15, 10}) || r.OutlierBefore != 5 || r.OutlierAfter != 0 {
+		t.Errorf("Resampled: %v before %d after %d", r.FrequencyDistribution, r.OutlierBefore, r.OutlierAfter)
+	}
+}
@@ -0,0 +1,89 @@
+package cruncher
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// reservoir maintains a uniform random sample of the values added using
+// Vitter's Algorithm R
+type reservoir struct {
+	values []int64
+	seen   int64
+	random *rand.Rand
+}
+
+func newReservoir(size int) *reservoir {
+	return &reservoir{
+		values: make([]int64, 0, size),
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (r *reservoir) add(value int64) {
+	r.seen++
+	if len(r.values) < cap(r.values) {
+		r.values = append(r.values, value)
+		return
+	}
+	if i := r.random.Int63n(r.seen); i < int64(len(r.values)) {
+		r.values[i] = value
+	}
+}
+
+// merge combines the samples so each value seen by either reservoir is
+// approximately equally likely to be retained
+func (r *reservoir) merge(other *reservoir) {
+	seen := r.seen + other.seen
+	mine := append([]int64(nil), r.values...)
+	theirs := append([]int64(nil), other.values...)
+	r.random.Shuffle(len(mine), func(i, j int) { mine[i], mine[j] = mine[j], mine[i] })
+	r.random.Shuffle(len(theirs), func(i, j int) { theirs[i], theirs[j] = theirs[j], theirs[i] })
+	r.values = r.values[:0]
+	for len(r.values) < cap(r.values) && len(mine)+len(theirs) > 0 {
+		if len(theirs) == 0 || (len(mine) > 0 && r.random.Int63n(seen) < r.seen) {
+			r.values = append(r.values, mine[0])
+			mine = mine[1:]
+		} else {
+			r.values = append(r.values, theirs[0])
+			theirs = theirs[1:]
+		}
+	}
+	r.seen = seen
+}
+
+// sorted returns a sorted copy of the sample
+func (r *reservoir) sorted() []int64 {
+	sample := append([]int64(nil), r.values...)
+	sort.Sort(int64arr(sample))
+	return sample
+}
+
+// quantilesFromSample computes the percentiles ps using the nearest rank in
+// the sorted Sample, so like the Median the 50th percentile of an even
+// number of values is the lower middle value
+func (is IntStats) quantilesFromSample(ps []float64) []int64 {
+	results := make([]int64, len(ps))
+	l := len(is.Sample)
+	for i, p := range ps {
+		switch {
+		case p <= 0:
+			results[i] = is.Min
+		case p >= 1:
+			results[i] = is.Max
+		default:
+			// The tolerance keeps ranks computed as rank/l from rounding up
+			rank := int(math.Ceil(p*float64(l)-1e-9)) - 1
+			if rank < 0 {
+				rank = 0
+			}
+			if rank >= l {
+				rank = l - 1
+			}
+			results[i] = is.Sample[rank]
+		}
+	}
+	return results
+}
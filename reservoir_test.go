@@ -0,0 +1,119 @@
+package cruncher
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestReservoir(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithReservoir(1000))
+	a.reservoir.random = rand.New(rand.NewSource(1))
+	r := rand.New(rand.NewSource(1))
+	values := make([]int64, 0, 200000)
+	for i := 0; i < cap(values); i++ {
+		v := r.Int63n(1000) * r.Int63n(1000)
+		values = append(values, v)
+		a.Add(v)
+	}
+	sort.Sort(int64arr(values))
+	is := a.GetStats()
+	if actual, correct := len(is.Sample), 1000; actual != correct {
+		t.Fatalf("Sample size: %d != %d", actual, correct)
+	}
+	if !sort.IsSorted(int64arr(is.Sample)) {
+		t.Errorf("Sample should be sorted")
+	}
+	median := values[len(values)/2]
+	p50 := is.Percentile(0.5)
+	rank := float64(sort.Search(len(values), func(i int) bool { return values[i] > p50 })) / float64(len(values))
+	if actual, correct := rank, 0.5; math.Abs(actual-correct) > 0.05 {
+		t.Errorf("p50 %d has rank %f rather than %f (true median %d)", p50, actual, correct, median)
+	}
+	if actual, correct := p50, is.Sample[499]; actual != correct {
+		t.Errorf("p50: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Percentile(1), is.Max; actual != correct {
+		t.Errorf("p100: %d != %d", actual, correct)
+	}
+}
+
+func TestReservoirSmall(t *testing.T) {
+	r := newReservoir(10)
+	for i := int64(9); i >= 0; i-- {
+		r.add(i)
+	}
+	if actual, correct := r.sorted(), []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}; !equalInt64s(actual, correct) {
+		t.Errorf("Sample: %v != %v", actual, correct)
+	}
+	r.add(100)
+	if actual, correct := len(r.values), 10; actual != correct {
+		t.Errorf("Sample size: %d != %d", actual, correct)
+	}
+}
+
+func TestReservoirInvalidSize(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithReservoir(-1))
+	a.Add(1)
+	if a.reservoir != nil || a.GetStats().Sample != nil {
+		t.Errorf("A negative size shouldn't retain a sample")
+	}
+}
+
+func equalInt64s(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAccumulatorPercentile(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithReservoir(1000))
+	if _, ok := a.Percentile(0.5); ok {
+		t.Errorf("Percentile of no values should fail")
+	}
+	for i := int64(1); i <= 100; i++ {
+		a.Add(i)
+	}
+	if actual, ok := a.Percentile(0.5); !ok || actual != 50 {
+		t.Errorf("First p50: %d, %t != 50", actual, ok)
+	}
+	for i := int64(101); i <= 300; i++ {
+		a.Add(i)
+	}
+	if actual, ok := a.Percentile(0.5); !ok || actual != 150 {
+		t.Errorf("Second p50: %d, %t != 150", actual, ok)
+	}
+	if actual, correct := a.GetStats().Count, int64(300); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+
+	d := NewAccumulatorWithOptions(WithTDigest(100))
+	for i := int64(1); i <= 1000; i++ {
+		d.Add(i)
+	}
+	polled, ok := d.Percentile(0.9)
+	if !ok || polled != d.GetStats().Percentile(0.9) {
+		t.Errorf("t-digest p90: %d, %t != %d", polled, ok, d.GetStats().Percentile(0.9))
+	}
+
+	p := NewAccumulatorWithOptions(WithP2Quantiles(0.5))
+	p.Add(1)
+	if _, ok := p.Percentile(0.5); !ok {
+		t.Errorf("P² p50 should be available")
+	}
+	if _, ok := p.Percentile(0.9); ok {
+		t.Errorf("P² p90 isn't tracked")
+	}
+	n := NewAccumulator(10, 10)
+	n.Add(1)
+	if _, ok := n.Percentile(0.5); ok {
+		t.Errorf("Percentile without a backend should fail")
+	}
+}
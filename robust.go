@@ -0,0 +1,72 @@
+package cruncher
+
+import "math"
+
+// TrimmedMean returns the mean of the values after discarding the smallest
+// and largest fraction (0.0 - 0.5) of them, so it's not swayed by
+// extreme values. It uses the Sample when one was retained, otherwise the
+// frequency distribution assuming values are evenly spread within a bucket.
+func (is IntStats) TrimmedMean(fraction float64) float64 {
+	if is.Count == 0 {
+		return 0
+	}
+	sum, kept, _, _, _ := is.trim(fraction)
+	return sum / kept
+}
+
+// WinsorizedMean returns the mean of the values after replacing the
+// smallest and largest fraction (0.0 - 0.5) of them with the smallest and
+// largest of the values that remain. Unlike TrimmedMean the extreme values
+// still count, but only as much as the values at the percentiles they're
+// clamped to. It uses the same data as TrimmedMean.
+func (is IntStats) WinsorizedMean(fraction float64) float64 {
+	if is.Count == 0 {
+		return 0
+	}
+	sum, kept, trimmed, low, high := is.trim(fraction)
+	return (sum + trimmed*(low+high)) / (kept + 2*trimmed)
+}
+
+// trim returns the sum and number of the values that are kept after
+// trimming fraction of the values from each end, the number trimmed from
+// each end and the smallest and largest values kept
+func (is IntStats) trim(fraction float64) (sum, kept, trimmed, low, high float64) {
+	fraction = math.Max(0, math.Min(fraction, 0.5))
+	if n := len(is.Sample); n > 0 {
+		k := int(fraction * float64(n))
+		if 2*k >= n {
+			k = (n - 1) / 2
+		}
+		for _, v := range is.Sample[k : n-k] {
+			sum += float64(v)
+		}
+		return sum, float64(n - 2*k), float64(k), float64(is.Sample[k]), float64(is.Sample[n-1-k])
+	}
+	n := float64(is.Count)
+	trimmed = math.Floor(fraction * n)
+	if 2*trimmed >= n {
+		trimmed = math.Floor((n - 1) / 2)
+	}
+	// Ranks from trimmed to n - trimmed are kept
+	var rank float64
+	low, high = math.NaN(), math.NaN()
+	for _, b := range is.Buckets() {
+		count := float64(b.Count)
+		from, to := math.Max(rank, trimmed), math.Min(rank+count, n-trimmed)
+		if to > from {
+			// Spread the values evenly from LowerBound - 0.5 to UpperBound + 0.5
+			width := float64(b.UpperBound) - float64(b.LowerBound) + 1
+			start := float64(b.LowerBound) - 0.5
+			first := start + (from-rank)/count*width
+			last := start + (to-rank)/count*width
+			sum += (to - from) * (first + last) / 2
+			kept += to - from
+			if math.IsNaN(low) {
+				low = first + width/count/2
+			}
+			high = last - width/count/2
+		}
+		rank += count
+	}
+	return sum, kept, trimmed, low, high
+}
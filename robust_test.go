@@ -0,0 +1,57 @@
+package cruncher
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRobustMeans(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithExactMode())
+	for i := int64(1); i <= 90; i++ {
+		a.Add(100 + i%10)
+	}
+	for i := 0; i < 10; i++ {
+		a.Add(100000)
+	}
+	is := a.GetStats()
+	trimmed, winsorized := is.TrimmedMean(0.1), is.WinsorizedMean(0.1)
+	// Each of 100 - 109 is added 9 times. Trimming 10% discards the extreme
+	// values along with nine 100s and a 101.
+	if actual, correct := trimmed, 105.05; math.Abs(actual-correct) > 1e-9 {
+		t.Errorf("TrimmedMean: %f != %f", actual, correct)
+	}
+	// Winsorizing replaces them with 101 and 109 instead
+	if actual, correct := winsorized, 105.04; math.Abs(actual-correct) > 1e-9 {
+		t.Errorf("WinsorizedMean: %f != %f", actual, correct)
+	}
+	if is.Mean < 10000 {
+		t.Errorf("Mean should be dominated by the extreme values: %f", is.Mean)
+	}
+	if actual, correct := is.TrimmedMean(0), is.Mean; math.Abs(actual-correct) > 1e-9 {
+		t.Errorf("Untrimmed mean: %f != %f", actual, correct)
+	}
+}
+
+func TestRobustMeansFromDistribution(t *testing.T) {
+	is := IntStats{
+		Min:                                0,
+		Max:                                99,
+		Count:                              100,
+		BucketSize:                         10,
+		FrequencyDistributionStartingValue: 0,
+		FrequencyDistribution:              []int64{10, 10, 10, 10, 10, 10, 10, 10, 10, 10},
+	}
+	for _, fraction := range []float64{0, 0.1, 0.25} {
+		if actual, correct := is.TrimmedMean(fraction), 49.5; math.Abs(actual-correct) > 1e-9 {
+			t.Errorf("TrimmedMean(%g): %f != %f", fraction, actual, correct)
+		}
+		if actual, correct := is.WinsorizedMean(fraction), 49.5; math.Abs(actual-correct) > 1e-9 {
+			t.Errorf("WinsorizedMean(%g): %f != %f", fraction, actual, correct)
+		}
+	}
+	is.FrequencyDistribution[9] = 910
+	is.Count = 1000
+	if trimmed, winsorized := is.TrimmedMean(0.1), is.WinsorizedMean(0.1); trimmed <= 90 || winsorized <= 90 {
+		t.Errorf("Skewed means: %f %f", trimmed, winsorized)
+	}
+}
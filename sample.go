@@ -0,0 +1,47 @@
+package cruncher
+
+import (
+	"math"
+	"time"
+)
+
+// sampler includes values with a fixed probability using a xorshift64*
+// generator, which is much cheaper than math/rand for a decision per value
+type sampler struct {
+	state     uint64
+	threshold uint64
+}
+
+func newSampler(rate float64) *sampler {
+	return &sampler{
+		state:     uint64(time.Now().UnixNano()) | 1,
+		threshold: uint64(rate * math.MaxUint64),
+	}
+}
+
+// include reports whether the next value is part of the sample
+func (s *sampler) include() bool {
+	s.state ^= s.state >> 12
+	s.state ^= s.state << 25
+	s.state ^= s.state >> 27
+	return s.state*2685821657736338717 < s.threshold
+}
+
+// EstimatedCount estimates the number of values offered to Add, including
+// those excluded WithSampleRate, by scaling Count up by 1/SampleRate. It's
+// Count when the data wasn't sampled.
+func (is IntStats) EstimatedCount() float64 {
+	if is.SampleRate <= 0 || is.SampleRate >= 1 {
+		return float64(is.Count)
+	}
+	return float64(is.Count) / is.SampleRate
+}
+
+// EstimatedSum estimates the total of the values offered to Add, including
+// those excluded WithSampleRate, from the Mean and EstimatedCount
+func (is IntStats) EstimatedSum() float64 {
+	if is.Count == 0 {
+		return 0
+	}
+	return is.Mean * is.EstimatedCount()
+}
@@ -0,0 +1,58 @@
+package cruncher
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestSampleRate(t *testing.T) {
+	full := NewAccumulator(1000, 20)
+	sampled := NewAccumulatorWithOptions(WithSampleRate(0.1), WithWindow(1000), WithBuckets(20))
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 1000000; i++ {
+		v := int64(r.NormFloat64()*100 + 1000)
+		full.Add(v)
+		sampled.Add(v)
+	}
+	f, s := full.GetStats(), sampled.GetStats()
+	if actual, correct := float64(s.Count), 100000.0; math.Abs(actual-correct) > 2000 {
+		t.Errorf("Sampled count: %f != %f", actual, correct)
+	}
+	if actual, correct := s.EstimatedCount(), float64(f.Count); math.Abs(actual-correct)/correct > 0.02 {
+		t.Errorf("EstimatedCount: %f != %f", actual, correct)
+	}
+	fullSum, _ := new(big.Float).SetInt(f.Sum).Float64()
+	if actual, correct := s.EstimatedSum(), fullSum; math.Abs(actual-correct)/correct > 0.02 {
+		t.Errorf("EstimatedSum: %f != %f", actual, correct)
+	}
+	if actual, correct := s.Mean, f.Mean; math.Abs(actual-correct) > 2 {
+		t.Errorf("Mean: %f != %f", actual, correct)
+	}
+	if actual, correct := s.StdDev, f.StdDev; math.Abs(actual-correct) > 2 {
+		t.Errorf("StdDev: %f != %f", actual, correct)
+	}
+	if actual, correct := s.Median, f.Median; math.Abs(float64(actual-correct)) > 10 {
+		t.Errorf("Median: %d != %d", actual, correct)
+	}
+	if actual, correct := f.EstimatedCount(), float64(f.Count); actual != correct {
+		t.Errorf("Unsampled EstimatedCount: %f != %f", actual, correct)
+	}
+}
+
+func TestSampleRateIgnored(t *testing.T) {
+	for _, rate := range []float64{0, -0.5, 1} {
+		a := NewAccumulatorWithOptions(WithSampleRate(rate))
+		for v := int64(0); v < 100; v++ {
+			a.Add(v)
+		}
+		is := a.GetStats()
+		if actual, correct := is.Count, int64(100); actual != correct {
+			t.Errorf("Count at rate %f: %d != %d", rate, actual, correct)
+		}
+		if actual, correct := is.SampleRate, 0.0; actual != correct {
+			t.Errorf("SampleRate at rate %f: %f != %f", rate, actual, correct)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package cruncher
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Scale selects how values are grouped into the buckets of the frequency
+// distribution
+type Scale int
+
+const (
+	// LinearScale groups values into equally sized buckets
+	LinearScale Scale = iota
+	// SymLogScale groups values by their order of magnitude (in powers of
+	// 2) with negative values mirrored into their own buckets and a
+	// dedicated bucket for zero. For example 4 - 7, 0 and -7 - -4 each
+	// occupy a bucket. It's useful for data spanning several orders of
+	// magnitude, including negative values, where equally sized buckets
+	// would place almost all the values in a single bucket.
+	// In this scale FrequencyDistributionStartingValue is the signed order
+	// of magnitude of the first bucket and BucketSize is 1.
+	SymLogScale
+)
+
+// symLogClass returns the signed order of magnitude of value, 0 for zero,
+// n for values from 2^(n-1) to 2^n - 1 and -n for their negatives.
+func symLogClass(value int64) int64 {
+	switch {
+	case value > 0:
+		return int64(bits.Len64(uint64(value)))
+	case value < 0:
+		return -int64(bits.Len64(uint64(-value)))
+	}
+	return 0
+}
+
+// symLogBounds returns the smallest and largest values with the signed
+// order of magnitude class
+func symLogBounds(class int64) (lower, upper int64) {
+	switch {
+	case class > 0:
+		if class >= 64 {
+			return math.MaxInt64, math.MaxInt64
+		}
+		return 1 << (class - 1), int64(uint64(1)<<class - 1)
+	case class < 0:
+		if class <= -64 {
+			return math.MinInt64, math.MinInt64
+		}
+		return -int64(uint64(1)<<-class - 1), -(1 << (-class - 1))
+	}
+	return 0, 0
+}
+
+// scaled maps a value onto the scale of the distribution
+func (is IntStats) scaled(value int64) int64 {
+	if is.Scale == SymLogScale {
+		return symLogClass(value)
+	}
+	return value
+}
+
+// bucketBounds returns the smallest and largest values within bucket i
+func (is IntStats) bucketBounds(i int) (lower, upper int64) {
+	if is.Scale == SymLogScale {
+		return symLogBounds(is.FrequencyDistributionStartingValue + int64(i))
+	}
+	lower = addClamped(is.FrequencyDistributionStartingValue, uint64(is.BucketSize)*uint64(i))
+	return lower, addClamped(lower, uint64(is.BucketSize)-1)
+}
+
+// addClamped returns a + b limited to MaxInt64
+func addClamped(a int64, b uint64) int64 {
+	if b > uint64(math.MaxInt64)-uint64(a) {
+		return math.MaxInt64
+	}
+	return int64(uint64(a) + b)
+}
@@ -0,0 +1,52 @@
+package cruncher
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSymLogBuckets(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithSymLogBuckets())
+	values := []int64{-1000, -10, 0, 10, 1000, -1001, 11, 1000}
+	for _, v := range values {
+		a.Add(v)
+	}
+	stats := a.GetStats()
+	seen := map[int]bool{}
+	for _, v := range []int64{-1000, -10, 0, 10, 1000} {
+		offset := stats.bucketOffset(v)
+		if seen[offset] {
+			t.Errorf("%d shares bucket %d", v, offset)
+		}
+		seen[offset] = true
+	}
+	buckets := stats.Buckets()
+	var total int64
+	for i, b := range buckets {
+		total += b.Count
+		if b.IsOutlier {
+			t.Errorf("Bucket %d is an outlier", i)
+		}
+		if i > 0 && b.LowerBound != buckets[i-1].UpperBound+1 {
+			t.Errorf("Bucket %d isn't contiguous %d != %d", i, b.LowerBound, buckets[i-1].UpperBound+1)
+		}
+	}
+	if actual, correct := total, int64(len(values)); actual != correct {
+		t.Errorf("Total: %d != %d", actual, correct)
+	}
+	if count, lower, upper := stats.BucketCountFor(1000); count != 2 || lower != 512 || upper != 1023 {
+		t.Errorf("BucketCountFor: %d [%d - %d]", count, lower, upper)
+	}
+	if count, lower, upper := stats.BucketCountFor(-1000); count != 2 || lower != -1023 || upper != -512 {
+		t.Errorf("BucketCountFor: %d [%d - %d]", count, lower, upper)
+	}
+}
+
+func TestSymLogBounds(t *testing.T) {
+	for _, v := range []int64{math.MinInt64, -1025, -1, 0, 1, 7, 8, 1 << 40, math.MaxInt64} {
+		lower, upper := symLogBounds(symLogClass(v))
+		if v < lower || v > upper {
+			t.Errorf("%d not within [%d - %d]", v, lower, upper)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package cruncher
+
+import (
+	"fmt"
+	"math"
+)
+
+// Entropy returns the Shannon entropy, in bits, of the fraction of values in
+// each bucket, including the outliers. It's log2 of the number of buckets
+// when the values are spread evenly and 0 when they're all in one bucket.
+func (is IntStats) Entropy() float64 {
+	var entropy float64
+	for _, p := range is.DistributionFractions() {
+		if p > 0 {
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// DistanceMetric selects how Distance compares two distributions
+type DistanceMetric int
+
+const (
+	// TotalVariation is half the sum of the absolute differences between
+	// the fractions of values in each bucket, from 0 to 1
+	TotalVariation DistanceMetric = iota
+	// JensenShannon is the square root of the Jensen-Shannon divergence,
+	// in bits, of the fractions of values in each bucket, from 0 to 1
+	JensenShannon
+)
+
+// Distance measures how different the distributions of is and other are,
+// it's 0 for identical distributions and 1 for disjoint ones. The outliers
+// are compared as buckets before and after the distribution. The
+// distributions must have the same layout, see SameLayout.
+func (is IntStats) Distance(other IntStats, metric DistanceMetric) (float64, error) {
+	if !is.SameLayout(other) {
+		return 0, layoutError(is, other)
+	}
+	p, q := is.alignedFractions(), other.alignedFractions()
+	var distance float64
+	switch metric {
+	case TotalVariation:
+		for i := range p {
+			distance += math.Abs(p[i]-q[i]) / 2
+		}
+	case JensenShannon:
+		for i := range p {
+			m := (p[i] + q[i]) / 2
+			if p[i] > 0 {
+				distance += p[i] * math.Log2(p[i]/m) / 2
+			}
+			if q[i] > 0 {
+				distance += q[i] * math.Log2(q[i]/m) / 2
+			}
+		}
+		// Rounding can leave the divergence slightly negative
+		distance = math.Sqrt(math.Max(distance, 0))
+	default:
+		return 0, fmt.Errorf("%w: unknown distance metric %d", ErrInvalid, metric)
+	}
+	return distance, nil
+}
+
+// alignedFractions returns the fraction of values in each bucket, preceded
+// and followed by the fraction of outliers before and after, even if there
+// aren't any
+func (is IntStats) alignedFractions() []float64 {
+	fractions := make([]float64, 0, len(is.FrequencyDistribution)+2)
+	fractions = append(fractions, is.fraction(is.OutlierBefore))
+	for _, count := range is.FrequencyDistribution {
+		fractions = append(fractions, is.fraction(count))
+	}
+	return append(fractions, is.fraction(is.OutlierAfter))
+}
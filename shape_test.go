@@ -0,0 +1,41 @@
+package cruncher
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEntropy(t *testing.T) {
+	uniform := IntStats{Count: 80, BucketSize: 1, FrequencyDistribution: []int64{10, 10, 10, 10, 10, 10, 10, 10}}
+	if actual, correct := uniform.Entropy(), math.Log2(8); math.Abs(actual-correct) > 1e-9 {
+		t.Errorf("Uniform entropy: %f != %f", actual, correct)
+	}
+	spike := IntStats{Count: 100, BucketSize: 1, FrequencyDistribution: []int64{0, 0, 100, 0}}
+	if actual, correct := spike.Entropy(), 0.0; actual != correct {
+		t.Errorf("Spike entropy: %f != %f", actual, correct)
+	}
+	outliers := IntStats{Count: 4, BucketSize: 1, FrequencyDistribution: []int64{2}, OutlierBefore: 1, OutlierAfter: 1}
+	if actual, correct := outliers.Entropy(), 1.5; math.Abs(actual-correct) > 1e-9 {
+		t.Errorf("Entropy with outliers: %f != %f", actual, correct)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	a := IntStats{Count: 30, BucketSize: 10, FrequencyDistribution: []int64{10, 20, 0, 0}}
+	b := IntStats{Count: 60, BucketSize: 10, FrequencyDistribution: []int64{0, 0, 50, 0}, OutlierAfter: 10}
+	for _, metric := range []DistanceMetric{TotalVariation, JensenShannon} {
+		if d, err := a.Distance(a, metric); err != nil || d != 0 {
+			t.Errorf("Metric %d identical distance: %f %v", metric, d, err)
+		}
+		if d, err := a.Distance(b, metric); err != nil || math.Abs(d-1) > 1e-9 {
+			t.Errorf("Metric %d disjoint distance: %f %v", metric, d, err)
+		}
+	}
+	c := IntStats{Count: 30, BucketSize: 10, FrequencyDistribution: []int64{20, 10, 0, 0}}
+	if d, _ := a.Distance(c, TotalVariation); math.Abs(d-1.0/3) > 1e-9 {
+		t.Errorf("Total variation: %f != %f", d, 1.0/3)
+	}
+	if _, err := a.Distance(IntStats{BucketSize: 5, FrequencyDistribution: []int64{1}}, TotalVariation); err == nil {
+		t.Errorf("Different layouts should be an error")
+	}
+}
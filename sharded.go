@@ -0,0 +1,75 @@
+package cruncher
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ShardedAccumulator accumulates values added concurrently by many
+// goroutines. There's one Accumulator per processor, each with its own
+// lock, and goroutines keep adding to the shard last used on their
+// processor so they rarely wait on each other. The shards are merged by
+// GetStats.
+//
+// Each shard lays out its frequency distribution from the values it
+// receives, GetStats resamples linear distributions with different layouts
+// onto a common layout as MergeStats does.
+type ShardedAccumulator struct {
+	opts   []Option
+	next   atomic.Uint64
+	shards []shard
+	// local caches a shard per processor
+	local sync.Pool
+}
+
+// shard is padded so the locks of neighboring shards don't share a cache line
+type shard struct {
+	sync.Mutex
+	a *Accumulator
+	_ [48]byte
+}
+
+// NewShardedAccumulator allocates a shard per GOMAXPROCS, each configured
+// with opts
+func NewShardedAccumulator(opts ...Option) *ShardedAccumulator {
+	s := &ShardedAccumulator{
+		opts:   opts,
+		shards: make([]shard, runtime.GOMAXPROCS(0)),
+	}
+	for i := range s.shards {
+		s.shards[i].a = NewAccumulatorWithOptions(opts...)
+	}
+	return s
+}
+
+// Add adds a value to the shard of the calling processor, it's safe to
+// call concurrently
+func (s *ShardedAccumulator) Add(value int64) {
+	sh, _ := s.local.Get().(*shard)
+	if sh == nil {
+		// The processor has no shard yet or it was dropped by the GC
+		sh = &s.shards[s.next.Add(1)%uint64(len(s.shards))]
+	}
+	sh.Lock()
+	sh.a.Add(value)
+	sh.Unlock()
+	s.local.Put(sh)
+}
+
+// GetStats merges the shards into the stats of all the values added. An
+// error is returned if the shards' distributions have different layouts
+// that can't be resampled, such as with WithSymLogBuckets.
+func (s *ShardedAccumulator) GetStats() (IntStats, error) {
+	merged := NewAccumulatorWithOptions(s.opts...)
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.Lock()
+		err := merged.mergeResampled(sh.a)
+		sh.Unlock()
+		if err != nil {
+			return IntStats{}, err
+		}
+	}
+	return merged.GetStats(), nil
+}
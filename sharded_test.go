@@ -0,0 +1,107 @@
+package cruncher
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestShardedAccumulator(t *testing.T) {
+	const goroutines, values = 16, 5000
+	s := NewShardedAccumulator(WithWindow(1<<20), WithBuckets(10))
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < values; i++ {
+				s.Add(int64(g*values + i))
+			}
+		}(g)
+	}
+	wg.Wait()
+	is, err := s.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := int64(goroutines * values)
+	if actual, correct := is.Count, n; actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	if is.Min != 0 || is.Max != n-1 {
+		t.Errorf("Range: %d - %d", is.Min, is.Max)
+	}
+	if actual, correct := is.Sum, big.NewInt(n*(n-1)/2); actual.Cmp(correct) != 0 {
+		t.Errorf("Sum: %s != %s", actual, correct)
+	}
+	var total int64
+	for _, b := range is.Buckets() {
+		total += b.Count
+	}
+	if actual, correct := total, n; actual != correct {
+		t.Errorf("Distribution total: %d != %d", actual, correct)
+	}
+}
+
+func TestShardedAccumulatorLayouts(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	s := NewShardedAccumulator()
+	if actual, correct := len(s.shards), 4; actual != correct {
+		t.Fatalf("Shards: %d != %d", actual, correct)
+	}
+	// Fill each shard's window with a different range so every shard lays
+	// out its own distribution
+	var n int64
+	for i := range s.shards {
+		for v := 0; v < 2*DefaultApproximationWindow; v++ {
+			s.shards[i].a.Add(int64(i*1000 + v%(100*(i+1))))
+			n++
+		}
+	}
+	if s.shards[0].a.intStats.SameLayout(s.shards[3].a.intStats) {
+		t.Fatal("Shards should have different layouts")
+	}
+	is, err := s.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, correct := is.Count, n; actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	total := is.OutlierBefore + is.OutlierAfter
+	for _, c := range is.FrequencyDistribution {
+		total += c
+	}
+	if actual, correct := total, n; actual != correct {
+		t.Errorf("Distribution total: %d != %d", actual, correct)
+	}
+	if is.Min != 0 || is.Max != 3399 {
+		t.Errorf("Range: %d - %d", is.Min, is.Max)
+	}
+}
+
+func BenchmarkShardedAdd(b *testing.B) {
+	s := NewShardedAccumulator(WithWindow(100))
+	b.RunParallel(func(pb *testing.PB) {
+		var v int64
+		for pb.Next() {
+			v++
+			s.Add(v % 1000)
+		}
+	})
+}
+
+func BenchmarkMutexAdd(b *testing.B) {
+	a := NewAccumulatorWithOptions(WithWindow(100))
+	var mu sync.Mutex
+	b.RunParallel(func(pb *testing.PB) {
+		var v int64
+		for pb.Next() {
+			v++
+			mu.Lock()
+			a.Add(v % 1000)
+			mu.Unlock()
+		}
+	})
+}
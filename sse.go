@@ -0,0 +1,70 @@
+package cruncher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultStreamInterval is used by StreamStats when interval isn't positive
+const defaultStreamInterval = time.Second
+
+// StreamStats writes a Snapshot of the stats as a Server-Sent Event, see
+// StreamStatsFunc. Values may be added by other goroutines while the stats
+// are streamed as long as they hold mu while calling Add, mu is held while
+// each Snapshot is taken. mu may be nil when no values are added
+// concurrently.
+func (a *Accumulator) StreamStats(ctx context.Context, w http.ResponseWriter, interval time.Duration, mu sync.Locker) {
+	StreamStatsFunc(ctx, w, interval, func() IntStats {
+		if mu != nil {
+			mu.Lock()
+			defer mu.Unlock()
+		}
+		return a.Snapshot()
+	})
+}
+
+// StreamStatsFunc writes the stats returned by stats as a Server-Sent
+// Event, a "data:" frame holding the WriteJSON encoding, immediately and
+// then every interval until ctx is done, such as when the client
+// disconnects and the request's context is cancelled. An interval that
+// isn't positive streams every second. stats is called from the streaming
+// goroutine so it must be safe to call while values are being added, such
+// as the Lifetime of a DualAccumulator.
+func StreamStatsFunc(ctx context.Context, w http.ResponseWriter, interval time.Duration, stats func() IntStats) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher, _ := w.(http.Flusher)
+	if interval <= 0 {
+		interval = defaultStreamInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var encoded, frame bytes.Buffer
+	for {
+		encoded.Reset()
+		frame.Reset()
+		if err := stats().WriteJSON(&encoded); err != nil {
+			return
+		}
+		// A frame's data must be a single line
+		if err := json.Compact(&frame, encoded.Bytes()); err != nil {
+			return
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", frame.Bytes()); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package cruncher
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamStats(t *testing.T) {
+	a := NewAccumulator(100, 10)
+	for i := int64(1); i <= 50; i++ {
+		a.Add(i)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 35*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	a.StreamStats(ctx, w, 10*time.Millisecond, nil)
+
+	if actual, correct := w.Header().Get("Content-Type"), "text/event-stream"; actual != correct {
+		t.Errorf("Content-Type: %s != %s", actual, correct)
+	}
+	if !w.Flushed {
+		t.Errorf("Frames should be flushed")
+	}
+	frames := 0
+	scanner := bufio.NewScanner(w.Body)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "data: ") {
+			t.Fatalf("Unexpected line: %q", line)
+		}
+		var is IntStats
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &is); err != nil {
+			t.Fatalf("Frame %d: %v", frames, err)
+		}
+		if actual, correct := is.Count, int64(50); actual != correct {
+			t.Errorf("Frame %d Count: %d != %d", frames, actual, correct)
+		}
+		frames++
+	}
+	if frames < 2 {
+		t.Errorf("Only %d frames were written", frames)
+	}
+}
+
+func TestStreamStatsInvalidInterval(t *testing.T) {
+	a := NewAccumulator(100, 10)
+	a.Add(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	a.StreamStats(ctx, w, 0, nil)
+	if actual, correct := strings.Count(w.Body.String(), "data: "), 1; actual != correct {
+		t.Errorf("Frames: %d != %d", actual, correct)
+	}
+}
+
+func TestStreamStatsConcurrentAdd(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithWindow(100), WithTDigest(50))
+	var mu sync.Mutex
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for v := int64(0); ctx.Err() == nil; v++ {
+			mu.Lock()
+			a.Add(v % 1000)
+			mu.Unlock()
+		}
+	}()
+	w := httptest.NewRecorder()
+	a.StreamStats(ctx, w, 5*time.Millisecond, &mu)
+	<-done
+	if frames := strings.Count(w.Body.String(), "data: "); frames < 2 {
+		t.Errorf("Only %d frames were written", frames)
+	}
+}
+
+func TestStreamStatsFunc(t *testing.T) {
+	d := NewDualAccumulator()
+	d.Add(7)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	StreamStatsFunc(ctx, w, time.Millisecond, d.Lifetime)
+	if !strings.Contains(w.Body.String(), `"Count":1,`) {
+		t.Errorf("Frames should hold the lifetime stats: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,140 @@
+package cruncher
+
+import (
+	"math"
+	"sort"
+)
+
+// Centroid summarizes Count values of a t-digest by their Mean
+type Centroid struct {
+	Mean  float64
+	Count float64
+}
+
+// tDigest estimates quantiles with a bounded number of centroids (the
+// merging t-digest by Dunning and Ertl). Centroids near the median may
+// absorb many values while those near the tails stay small, so the
+// estimates are most accurate for extreme quantiles. At most about
+// compression centroids are retained plus a buffer of pending values.
+type tDigest struct {
+	compression float64
+	centroids   []Centroid
+	buffer      []Centroid
+	count       float64
+	min, max    float64
+}
+
+func newTDigest(compression float64) *tDigest {
+	return &tDigest{compression: compression}
+}
+
+func (t *tDigest) add(value int64) {
+	v := float64(value)
+	if t.count == 0 || v < t.min {
+		t.min = v
+	}
+	if t.count == 0 || v > t.max {
+		t.max = v
+	}
+	t.count++
+	t.buffer = append(t.buffer, Centroid{Mean: v, Count: 1})
+	if float64(len(t.buffer)) >= 5*t.compression {
+		t.compress()
+	}
+}
+
+// merge adds the centroids of other
+func (t *tDigest) merge(other *tDigest) {
+	if other.count == 0 {
+		return
+	}
+	if t.count == 0 || other.min < t.min {
+		t.min = other.min
+	}
+	if t.count == 0 || other.max > t.max {
+		t.max = other.max
+	}
+	t.count += other.count
+	t.buffer = append(t.buffer, other.centroids...)
+	t.buffer = append(t.buffer, other.buffer...)
+	t.compress()
+}
+
+// scale is the k1 scale function, centroids may only span a unit of it
+func (t *tDigest) scale(q float64) float64 {
+	return t.compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// compress merges the buffered values into the centroids
+func (t *tDigest) compress() {
+	if len(t.buffer) == 0 {
+		return
+	}
+	all := append(t.buffer, t.centroids...)
+	sort.Slice(all, func(i, j int) bool { return all[i].Mean < all[j].Mean })
+	merged := make([]Centroid, 0, len(t.centroids)+1)
+	current := all[0]
+	var before float64
+	for _, c := range all[1:] {
+		q0 := before / t.count
+		q2 := (before + current.Count + c.Count) / t.count
+		if t.scale(math.Min(q2, 1))-t.scale(q0) <= 1 {
+			current.Count += c.Count
+			current.Mean += (c.Mean - current.Mean) * c.Count / current.Count
+			continue
+		}
+		merged = append(merged, current)
+		before += current.Count
+		current = c
+	}
+	t.centroids = append(merged, current)
+	t.buffer = t.buffer[:0]
+}
+
+// compressed returns the centroids with the buffered values merged in
+// without modifying the digest
+func (t *tDigest) compressed() []Centroid {
+	c := *t
+	c.buffer = append([]Centroid(nil), t.buffer...)
+	c.compress()
+	return c.centroids
+}
+
+// quantilesFromCentroids estimates the percentiles ps by interpolating
+// between the centers of the Centroids, and Min and Max at the extremes
+func (is IntStats) quantilesFromCentroids(ps []float64) []int64 {
+	results := make([]int64, len(ps))
+	var total float64
+	for _, c := range is.Centroids {
+		total += c.Count
+	}
+	for i, p := range ps {
+		switch {
+		case p <= 0:
+			results[i] = is.Min
+		case p >= 1:
+			results[i] = is.Max
+		default:
+			results[i] = int64(math.Round(is.centroidQuantile(p * total)))
+		}
+	}
+	return results
+}
+
+// centroidQuantile returns the value with the given rank
+func (is IntStats) centroidQuantile(rank float64) float64 {
+	previous, previousCenter := float64(is.Min), 0.0
+	var cumulative float64
+	for _, c := range is.Centroids {
+		center := cumulative + c.Count/2
+		if rank < center {
+			return previous + (c.Mean-previous)*(rank-previousCenter)/(center-previousCenter)
+		}
+		previous, previousCenter = c.Mean, center
+		cumulative += c.Count
+	}
+	if cumulative <= previousCenter {
+		return float64(is.Max)
+	}
+	return previous + (float64(is.Max)-previous)*(rank-previousCenter)/(cumulative-previousCenter)
+}
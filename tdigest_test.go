@@ -0,0 +1,49 @@
+package cruncher
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestTDigestMerge(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	opts := []Option{WithTDigest(100), WithWindow(100), WithDistributionStart(0)}
+	total := NewAccumulatorWithOptions(opts...)
+	var values []int64
+	for shard := 0; shard < 8; shard++ {
+		a := NewAccumulatorWithOptions(opts...)
+		a.Add(1000000)
+		values = append(values, 1000000)
+		for i := 0; i < 20000; i++ {
+			v := int64(r.ExpFloat64() * 1000)
+			a.Add(v)
+			values = append(values, v)
+		}
+		if err := total.Merge(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Sort(int64arr(values))
+	is := total.GetStats()
+	if len(is.Centroids) == 0 || len(is.Centroids) > 200 {
+		t.Errorf("Centroids: %d", len(is.Centroids))
+	}
+	var count float64
+	for _, c := range is.Centroids {
+		count += c.Count
+	}
+	if actual, correct := count, float64(len(values)); actual != correct {
+		t.Errorf("Centroid count: %f != %f", actual, correct)
+	}
+	for _, p := range []float64{0.5, 0.9, 0.99} {
+		exact := float64(values[int(p*float64(len(values)))])
+		if actual := float64(is.Percentile(p)); math.Abs(actual-exact) > 0.02*exact {
+			t.Errorf("P%g: %f isn't within 2%% of %f", 100*p, actual, exact)
+		}
+	}
+	if actual, correct := is.Percentile(1), is.Max; actual != correct {
+		t.Errorf("P100: %d != %d", actual, correct)
+	}
+}
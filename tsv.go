@@ -0,0 +1,42 @@
+package cruncher
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteTSV writes the summary as "# key<tab>value" comment lines followed by
+// a tab separated table of the distribution's buckets, with a header row,
+// for tools that ignore lines starting with #.
+func (is IntStats) WriteTSV(w io.Writer) error {
+	sum := "0"
+	if is.Sum != nil {
+		sum = is.Sum.String()
+	}
+	summary := [][2]string{
+		{"name", is.Name},
+		{"count", strconv.FormatInt(is.Count, 10)},
+		{"min", strconv.FormatInt(is.Min, 10)},
+		{"max", strconv.FormatInt(is.Max, 10)},
+		{"sum", sum},
+		{"mean", strconv.FormatFloat(is.Mean, 'g', -1, 64)},
+		{"stddev", strconv.FormatFloat(is.StdDev, 'g', -1, 64)},
+		{"median", strconv.FormatInt(is.Median, 10)},
+		{"bucket_size", strconv.FormatInt(is.BucketSize, 10)},
+	}
+	for _, kv := range summary {
+		if _, err := fmt.Fprintf(w, "# %s\t%s\n", kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(w, "lower\tupper\tcount\tfraction\toutlier\n"); err != nil {
+		return err
+	}
+	for _, b := range is.Buckets() {
+		if _, err := fmt.Fprintf(w, "%d\t%d\t%d\t%g\t%t\n", b.LowerBound, b.UpperBound, b.Count, b.Fraction, b.IsOutlier); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package cruncher
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteTSV(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithWindow(100), WithBuckets(4), WithName("tsv"))
+	for i := int64(0); i < 40; i++ {
+		a.Add(i)
+	}
+	is := a.GetStats()
+	var buf bytes.Buffer
+	if err := is.WriteTSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	var table []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "# ") {
+			if fields := strings.Split(line[2:], "\t"); len(fields) != 2 {
+				t.Errorf("Summary line %q isn't a key and value", line)
+			}
+			continue
+		}
+		table = append(table, line)
+	}
+	if !strings.Contains(buf.String(), "# count\t40\n") || !strings.Contains(buf.String(), "# name\ttsv\n") {
+		t.Errorf("Summary is missing values:\n%s", buf.String())
+	}
+	if actual, correct := len(table), len(is.Buckets())+1; actual != correct {
+		t.Fatalf("Table rows: %d != %d\n%s", actual, correct, buf.String())
+	}
+	for _, row := range table {
+		if actual, correct := len(strings.Split(row, "\t")), 5; actual != correct {
+			t.Errorf("Row %q columns: %d != %d", row, actual, correct)
+		}
+	}
+	if actual, correct := table[1], "0\t9\t10\t0.25\tfalse"; actual != correct {
+		t.Errorf("First bucket: %q != %q", actual, correct)
+	}
+}
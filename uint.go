@@ -0,0 +1,88 @@
+package cruncher
+
+import (
+	"math"
+	"math/big"
+)
+
+// UintAccumulator collects statistics on uint64 values, including those
+// above math.MaxInt64 which would wrap to negative values if cast to int64.
+// Values are mapped onto int64 by flipping the sign bit which preserves
+// their order, so the median, distribution and frequency logic of
+// Accumulator are reused unchanged.
+type UintAccumulator struct {
+	a *Accumulator
+}
+
+// UintStats contains the stats accumulated by a UintAccumulator, the fields
+// have the same meaning as those of IntStats.
+type UintStats struct {
+	Name                               string
+	Min                                uint64
+	Max                                uint64
+	Count                              int64
+	Sum                                *big.Int
+	Mean                               float64
+	Variance                           float64
+	StdDev                             float64
+	Median                             uint64
+	FrequencyDistribution              []int64
+	BucketSize                         int64
+	FrequencyDistributionStartingValue uint64
+	OutlierBefore                      int64
+	OutlierAfter                       int64
+	ValueFrequency                     map[uint64]int64
+}
+
+// NewUintAccumulator allocates an accumulator for uint64 values configured
+// by opts, see NewAccumulatorWithOptions.
+func NewUintAccumulator(opts ...Option) *UintAccumulator {
+	return &UintAccumulator{a: NewAccumulatorWithOptions(opts...)}
+}
+
+// AddUint adds a value to the data set to be summarized
+func (u *UintAccumulator) AddUint(value uint64) {
+	u.a.Add(uintToOrdered(value))
+}
+
+// GetStats provides the current stats accumulated, see Accumulator.GetStats
+func (u *UintAccumulator) GetStats() UintStats {
+	is := u.a.GetStats()
+	us := UintStats{
+		Name:                               is.Name,
+		Min:                                orderedToUint(is.Min),
+		Max:                                orderedToUint(is.Max),
+		Count:                              is.Count,
+		Variance:                           is.Variance,
+		StdDev:                             is.StdDev,
+		Median:                             orderedToUint(is.Median),
+		FrequencyDistribution:              is.FrequencyDistribution,
+		BucketSize:                         is.BucketSize,
+		FrequencyDistributionStartingValue: orderedToUint(is.FrequencyDistributionStartingValue),
+		OutlierBefore:                      is.OutlierBefore,
+		OutlierAfter:                       is.OutlierAfter,
+	}
+	if is.Count > 0 {
+		// Undo the offset of -2^63 applied to every value
+		offset := new(big.Int).Lsh(big.NewInt(is.Count), 63)
+		us.Sum = offset.Add(offset, is.Sum)
+		us.Mean = is.Mean + math.Exp2(63)
+	}
+	if is.ValueFrequency != nil {
+		us.ValueFrequency = make(map[uint64]int64, len(is.ValueFrequency))
+		for v, f := range is.ValueFrequency {
+			us.ValueFrequency[orderedToUint(v)] = f
+		}
+	}
+	return us
+}
+
+// uintToOrdered maps a uint64 onto an int64 with the same relative order
+func uintToOrdered(value uint64) int64 {
+	return int64(value ^ 1<<63)
+}
+
+// orderedToUint is the inverse of uintToOrdered
+func orderedToUint(value int64) uint64 {
+	return uint64(value) ^ 1<<63
+}
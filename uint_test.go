@@ -0,0 +1,58 @@
+package cruncher
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestUintAccumulator(t *testing.T) {
+	u := NewUintAccumulator(WithBuckets(4))
+	values := []uint64{math.MaxUint64, 1 << 63, 10, math.MaxInt64 + 10}
+	sum := new(big.Int)
+	for _, v := range values {
+		u.AddUint(v)
+		sum.Add(sum, new(big.Int).SetUint64(v))
+	}
+	us := u.GetStats()
+	if actual, correct := us.Min, uint64(10); actual != correct {
+		t.Errorf("Min: %d != %d", actual, correct)
+	}
+	if actual, correct := us.Max, uint64(math.MaxUint64); actual != correct {
+		t.Errorf("Max: %d != %d", actual, correct)
+	}
+	if actual, correct := us.Count, int64(4); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	if us.Sum.Cmp(sum) != 0 {
+		t.Errorf("Sum: %s != %s", us.Sum, sum)
+	}
+	correctMean, _ := new(big.Float).Quo(new(big.Float).SetInt(sum), big.NewFloat(4)).Float64()
+	if actual := us.Mean; math.Abs(actual-correctMean)/correctMean > 1e-12 {
+		t.Errorf("Mean: %f != %f", actual, correctMean)
+	}
+	// The lower of the two middle values
+	if actual, correct := us.Median, uint64(1<<63); actual != correct {
+		t.Errorf("Median: %d != %d", actual, correct)
+	}
+	if actual, correct := us.ValueFrequency[math.MaxUint64], int64(1); actual != correct {
+		t.Errorf("Frequency: %d != %d", actual, correct)
+	}
+	if actual, correct := us.FrequencyDistributionStartingValue, uint64(10); actual != correct {
+		t.Errorf("Distribution start: %d != %d", actual, correct)
+	}
+}
+
+func TestUintOrdering(t *testing.T) {
+	values := []uint64{0, 1, math.MaxInt64, 1 << 63, math.MaxUint64}
+	for i := 1; i < len(values); i++ {
+		if uintToOrdered(values[i-1]) >= uintToOrdered(values[i]) {
+			t.Errorf("Order of %d and %d isn't preserved", values[i-1], values[i])
+		}
+	}
+	for _, v := range values {
+		if actual := orderedToUint(uintToOrdered(v)); actual != v {
+			t.Errorf("Round trip: %d != %d", actual, v)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package cruncher
+
+import "fmt"
+
+// Validate checks the internal consistency of the accumulator and returns an
+// error describing the first invariant that doesn't hold. It's intended for
+// debugging and testing and doesn't finalize the accumulator.
+func (a *Accumulator) Validate() error {
+	for level, values := range a.remedians {
+		if len(values) > a.appoximationWindow {
+			return fmt.Errorf("%w: remedian level %d has %d values, more than the window of %d", ErrInvalid,
+				level, len(values), a.appoximationWindow)
+		}
+	}
+	if a.intStats.Count < 0 {
+		return fmt.Errorf("%w: negative count %d", ErrInvalid, a.intStats.Count)
+	}
+	if a.intStats.Count == 0 {
+		return nil
+	}
+	is := a.intStats
+	if is.Min > is.Max {
+		return fmt.Errorf("%w: min %d is greater than max %d", ErrInvalid, is.Min, is.Max)
+	}
+	if median := a.Snapshot().Median; median < is.Min || median > is.Max {
+		return fmt.Errorf("%w: median %d is outside of the range %d - %d", ErrInvalid, median, is.Min, is.Max)
+	}
+	if len(is.FrequencyDistribution) == 0 {
+		return nil
+	}
+	if is.OutlierBefore < 0 || is.OutlierAfter < 0 {
+		return fmt.Errorf("%w: negative outlier counts %d and %d", ErrInvalid, is.OutlierBefore, is.OutlierAfter)
+	}
+	if is.BucketSize < 1 {
+		return fmt.Errorf("%w: bucket size %d is less than 1", ErrInvalid, is.BucketSize)
+	}
+	total := is.OutlierBefore + is.OutlierAfter
+	for _, count := range is.FrequencyDistribution {
+		total += count
+	}
+	if total != is.Count {
+		return fmt.Errorf("%w: distribution contains %d values but count is %d", ErrInvalid, total, is.Count)
+	}
+	return nil
+}
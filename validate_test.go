@@ -0,0 +1,50 @@
+package cruncher
+
+import (
+	"strings"
+	"testing"
+)
+
+func validAccumulator() *Accumulator {
+	a := NewAccumulator(100, 10)
+	for i := int64(0); i < 50; i++ {
+		a.Add(i * 3)
+	}
+	a.Summarize()
+	return a
+}
+
+func TestValidate(t *testing.T) {
+	if err := NewAccumulator(100, 10).Validate(); err != nil {
+		t.Errorf("Empty accumulator: %v", err)
+	}
+	if err := validAccumulator().Validate(); err != nil {
+		t.Errorf("Valid accumulator: %v", err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		corrupt  func(a *Accumulator)
+		expected string
+	}{
+		{"bucket count", func(a *Accumulator) { a.intStats.FrequencyDistribution[2]++ }, "distribution contains"},
+		{"outliers", func(a *Accumulator) { a.intStats.OutlierAfter = 5 }, "distribution contains"},
+		{"min", func(a *Accumulator) { a.intStats.Min = 1000 }, "greater than max"},
+		{"median", func(a *Accumulator) { a.intStats.Max = 10 }, "median"},
+		{"bucket size", func(a *Accumulator) { a.intStats.BucketSize = 0 }, "bucket size"},
+		{"remedian", func(a *Accumulator) { a.remedians[0] = make([]int64, 101) }, "remedian level 0"},
+		{"count", func(a *Accumulator) { a.intStats.Count = -1 }, "negative count"},
+		{"negative outliers", func(a *Accumulator) {
+			a.intStats.OutlierBefore, a.intStats.FrequencyDistribution[0] = -1, a.intStats.FrequencyDistribution[0]+1
+		}, "negative outlier"},
+	} {
+		a := validAccumulator()
+		test.corrupt(a)
+		err := a.Validate()
+		if err == nil {
+			t.Errorf("Corrupt %s wasn't detected", test.name)
+		} else if !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Corrupt %s: %q should contain %q", test.name, err, test.expected)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package cruncher
+
+// AddWithWeight adds value, as Add does, and adds weight, such as a byte
+// size, to the value's total weight so GetWeightedTermFrequency can rank
+// values by their importance rather than occurrences. Like the term
+// frequency at most approximationWindow values, or the capacity set
+// WithFrequencyCapacity, are tracked, so totals may be overstated once there
+// are more distinct values. Weights that aren't positive are ignored, as
+// are the weights of values skipped or excluded WithSampleRate.
+func (a *Accumulator) AddWithWeight(value, weight int64) {
+	count := a.intStats.Count
+	a.Add(value)
+	if weight <= 0 || a.intStats.Count == count {
+		return
+	}
+	if a.weighted == nil {
+		a.weighted = newSpaceSaving(a.termCapacity())
+	}
+	a.weighted.addCount(quantize(value, a.quantizeStep), weight)
+}
+
+// GetWeightedTermFrequency returns the terms with the largest total weight
+// given to AddWithWeight. The Frequency of each Pair is the total weight.
+func (is IntStats) GetWeightedTermFrequency(topN int) PairList {
+	return topTerms(is.WeightedFrequency, topN, nil, IntStats{}.ranksBefore)
+}
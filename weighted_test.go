@@ -0,0 +1,42 @@
+package cruncher
+
+import "testing"
+
+func TestWeightedTermFrequency(t *testing.T) {
+	a := NewAccumulator(100, 5)
+	for i := 0; i < 1000; i++ {
+		a.AddWithWeight(1, 10)
+		a.AddWithWeight(2, 1)
+	}
+	a.AddWithWeight(3, 1000000)
+	is := a.GetStats()
+	weighted := is.GetWeightedTermFrequency(2)
+	if len(weighted) != 2 || weighted[0] != (Pair{3, 1000000}) || weighted[1] != (Pair{1, 10000}) {
+		t.Errorf("Weighted terms: %v", weighted)
+	}
+	if actual, correct := is.ValueFrequency[3], int64(1); actual != correct {
+		t.Errorf("Frequency of 3: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Count, int64(2001); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+}
+
+func TestWeightedSampledAndCapacity(t *testing.T) {
+	a := NewAccumulatorWithOptions(WithSampleRate(0.5))
+	for i := 0; i < 1000; i++ {
+		a.AddWithWeight(1, 1)
+	}
+	is := a.GetStats()
+	if actual, correct := is.WeightedFrequency[1], is.Count; actual != correct {
+		t.Errorf("Weight of sampled values: %d != %d", actual, correct)
+	}
+
+	b := NewAccumulatorWithOptions(WithWindow(100), WithFrequencyCapacity(10))
+	for v := int64(0); v < 50; v++ {
+		b.AddWithWeight(v, 1)
+	}
+	if actual, correct := len(b.GetStats().WeightedFrequency), 10; actual != correct {
+		t.Errorf("Weighted terms: %d != %d", actual, correct)
+	}
+}
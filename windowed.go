@@ -0,0 +1,83 @@
+package cruncher
+
+// WindowedAccumulator maintains statistics over only the most recently
+// added values. Once the window is full each Add evicts the oldest value.
+// Min and Max are tracked with monotonic queues so Add remains
+// constant time (amortized) regardless of the window size.
+type WindowedAccumulator struct {
+	// Name optionally identifies the data set in printed reports
+	Name string
+
+	values []int64
+	added  int64
+	total  int128
+	// minQueue and maxQueue hold the sequence numbers of values that may
+	// still become the window minimum or maximum, oldest first
+	minQueue []int64
+	maxQueue []int64
+}
+
+// NewWindowedAccumulator allocates an accumulator that maintains statistics
+// over the last size values added.
+func NewWindowedAccumulator(size int) *WindowedAccumulator {
+	if size < 1 {
+		size = 1
+	}
+	w := new(WindowedAccumulator)
+	w.values = make([]int64, size)
+	return w
+}
+
+// Add adds a value to the window, evicting the oldest value if the window
+// is full.
+func (w *WindowedAccumulator) Add(value int64) {
+	size := int64(len(w.values))
+	seq := w.added
+	slot := seq % size
+	if seq >= size {
+		// Evict the oldest value
+		w.total.sub(w.values[slot])
+		oldest := seq - size
+		if w.minQueue[0] == oldest {
+			w.minQueue = w.minQueue[1:]
+		}
+		if w.maxQueue[0] == oldest {
+			w.maxQueue = w.maxQueue[1:]
+		}
+	}
+	w.values[slot] = value
+	w.total.add(value)
+	w.added++
+
+	for l := len(w.minQueue); l > 0 && w.values[w.minQueue[l-1]%size] >= value; l-- {
+		w.minQueue = w.minQueue[:l-1]
+	}
+	w.minQueue = append(w.minQueue, seq)
+	for l := len(w.maxQueue); l > 0 && w.values[w.maxQueue[l-1]%size] <= value; l-- {
+		w.maxQueue = w.maxQueue[:l-1]
+	}
+	w.maxQueue = append(w.maxQueue, seq)
+}
+
+// Count returns the number of values currently in the window
+func (w *WindowedAccumulator) Count() int64 {
+	if size := int64(len(w.values)); w.added > size {
+		return size
+	}
+	return w.added
+}
+
+// GetStats provides the Min, Max, Count and Mean of the values in the
+// window. The distribution, median and value frequency are not maintained
+// for windowed data.
+func (w *WindowedAccumulator) GetStats() IntStats {
+	is := IntStats{Name: w.Name, Count: w.Count()}
+	if is.Count == 0 {
+		return is
+	}
+	size := int64(len(w.values))
+	is.Min = w.values[w.minQueue[0]%size]
+	is.Max = w.values[w.maxQueue[0]%size]
+	is.Mean = w.total.Float64() / float64(is.Count)
+	return is
+}
@@ -0,0 +1,77 @@
+package cruncher
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWindowedAccumulator(t *testing.T) {
+	w := NewWindowedAccumulator(5)
+	if actual, correct := w.GetStats().Count, int64(0); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	for _, v := range []int64{100, -100, 3, 4, 5, 6, 7, 2} {
+		w.Add(v)
+	}
+	// Window now holds 4, 5, 6, 7, 2
+	is := w.GetStats()
+	if actual, correct := is.Count, int64(5); actual != correct {
+		t.Errorf("Count: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Min, int64(2); actual != correct {
+		t.Errorf("Min: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Max, int64(7); actual != correct {
+		t.Errorf("Max: %d != %d", actual, correct)
+	}
+	if actual, correct := is.Mean, float64(24)/5; actual != correct {
+		t.Errorf("Mean: %f != %f", actual, correct)
+	}
+}
+
+func TestWindowedAccumulatorRandom(t *testing.T) {
+	const size = 50
+	w := NewWindowedAccumulator(size)
+	var values []int64
+	for i := 0; i < 10000; i++ {
+		v := rand.Int63n(1000) - 500
+		values = append(values, v)
+		w.Add(v)
+		window := values
+		if len(window) > size {
+			window = window[len(window)-size:]
+		}
+		min, max := window[0], window[0]
+		for _, v := range window {
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		is := w.GetStats()
+		if is.Min != min || is.Max != max || is.Count != int64(len(window)) {
+			t.Fatalf("After %d adds: min %d != %d, max %d != %d, count %d != %d",
+				i+1, is.Min, min, is.Max, max, is.Count, len(window))
+		}
+	}
+}
+
+func TestWindowedAccumulatorOverflow(t *testing.T) {
+	w := NewWindowedAccumulator(3)
+	for _, v := range []int64{math.MinInt64, math.MaxInt64, math.MaxInt64, math.MaxInt64, -3} {
+		w.Add(v)
+	}
+	// Window now holds MaxInt64, MaxInt64, -3
+	if actual, correct := w.GetStats().Mean, (2*float64(math.MaxInt64)-3)/3; actual != correct {
+		t.Errorf("Mean: %f != %f", actual, correct)
+	}
+	for i := 0; i < 3; i++ {
+		w.Add(-1)
+	}
+	if actual, correct := w.GetStats().Mean, -1.0; actual != correct {
+		t.Errorf("Mean: %f != %f", actual, correct)
+	}
+}